@@ -2,14 +2,19 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
+	"os/signal"
 	"strings"
+	"sync"
 	"time"
 
 	"xsspect/scanner"
+	"xsspect/scanner/bypass"
+	"xsspect/scanner/report"
 )
 
 const (
@@ -18,17 +23,58 @@ const (
 
 // Config holds the application configuration from CLI arguments
 type Config struct {
-	URL              string
-	Params           []string
-	Method           string
-	StopOnHit        bool
-	ShowAll          bool
-	CustomPayload    string
-	PayloadFile      string
-	BrowserVerify    bool
-	ChromeDriverPath string
-	GenerateReport   bool
-	CSVOutput        string
+	URL                string
+	Params             []string
+	Method             string
+	StopOnHit          bool
+	ShowAll            bool
+	CustomPayload      string
+	PayloadFile        string
+	BrowserVerify      bool
+	ChromeDriverPath   string
+	GenerateReport     bool
+	CSVOutput          string
+	JSONReport         bool
+	JSONOutput         string
+	SARIFReport        bool
+	SARIFOutput        string
+	WAFDetect          bool
+	ScanMode           string
+	Crawl              bool
+	MaxCrawlDepth      int
+	CrawlCrossOrigin   bool
+	CrawlRespectRobots bool
+	SubmitURL          string
+	VerifyURL          string
+	Workers            int
+	RPS                float64
+	BrowserWorkers     int
+	CheckpointPath     string
+	ResumePath         string
+	ShowProgress       bool
+	MetricsAddr        string
+	ReportSink         string
+	RequestFile        string
+	Marker             string
+	Scheme             string
+	Headers            []string
+	Cookie             string
+	Contexts           []scanner.ReflectionContext
+	Bypass             []bypass.Name
+}
+
+// repeatableFlag collects every occurrence of a repeatable flag like
+// --header into a slice, the way flag.Value is meant to be used for
+// flags that can appear more than once on the command line
+type repeatableFlag []string
+
+func (f *repeatableFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *repeatableFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
 }
 
 func main() {
@@ -42,22 +88,45 @@ func main() {
 	// Print banner
 	printBanner()
 
-	// Validate URL
-	if err := scanner.ValidateURL(config.URL); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	// --request-file scans get their URL, method and injection point from
+	// the request file itself rather than --url/--params
+	var requestTemplate *scanner.RequestTemplate
+	if config.RequestFile != "" {
+		tmpl, err := scanner.ParseRequestFile(config.RequestFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if config.Cookie != "" {
+			tmpl = tmpl.WithHeader("Cookie", config.Cookie)
+		}
+		for _, header := range config.Headers {
+			name, value, ok := strings.Cut(header, ":")
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: invalid --header %q (want \"Name: Value\")\n", header)
+				os.Exit(1)
+			}
+			tmpl = tmpl.WithHeader(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+		requestTemplate = tmpl
+	} else {
+		// Validate URL
+		if err := scanner.ValidateURL(config.URL); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
-	// Validate HTTP method
-	if err := scanner.ValidateMethod(config.Method); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+		// Validate HTTP method
+		if err := scanner.ValidateMethod(config.Method); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
-	// Validate parameters
-	if len(config.Params) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: No parameters specified. Use --params to specify parameters.\n")
-		os.Exit(1)
+		// Validate parameters
+		if len(config.Params) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: No parameters specified. Use --params to specify parameters.\n")
+			os.Exit(1)
+		}
 	}
 
 	// Load payloads
@@ -98,9 +167,15 @@ func main() {
 	}
 
 	// Print scan info
-	fmt.Printf("\n[*] Target: %s\n", config.URL)
-	fmt.Printf("[*] Method: %s\n", config.Method)
-	fmt.Printf("[*] Parameters: %s\n", strings.Join(config.Params, ", "))
+	if requestTemplate != nil {
+		fmt.Printf("\n[*] Request file: %s\n", config.RequestFile)
+		fmt.Printf("[*] Marker: %s\n", config.Marker)
+		fmt.Printf("[*] Method: %s\n", requestTemplate.Method)
+	} else {
+		fmt.Printf("\n[*] Target: %s\n", config.URL)
+		fmt.Printf("[*] Method: %s\n", config.Method)
+		fmt.Printf("[*] Parameters: %s\n", strings.Join(config.Params, ", "))
+	}
 	fmt.Printf("[*] Payloads loaded: %d\n", len(payloads))
 	fmt.Printf("[*] Stop on hit: %v\n", config.StopOnHit)
 	fmt.Printf("[*] Show all: %v\n", config.ShowAll)
@@ -110,6 +185,32 @@ func main() {
 	}
 	fmt.Println()
 
+	// Probe for a WAF before scanning so we can tell "blocked" apart from
+	// "not vulnerable". Not supported yet against a --request-file target
+	// since DetectWAF only knows how to probe a --url/--params pair.
+	var waf *scanner.WAFInfo
+	var session *scanner.CookieSession
+	if config.WAFDetect && requestTemplate == nil {
+		fmt.Println("[*] Probing target for a WAF...")
+		detected, err := scanner.DetectWAF(config.URL, config.Params[0], config.Method)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] WAF detection failed: %v\n", err)
+		} else if detected != nil {
+			waf = detected
+			fmt.Printf("[!] Detected WAF: %s (%s) — payloads blocked by it will be reported as BLOCKED, not a clean miss\n\n", waf.Name, waf.Vendor)
+
+			session, err = scanner.NewCookieSession(config.URL, config.Method)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Failed to start a cookie session for the detected WAF: %v\n", err)
+				session = nil
+			} else {
+				fmt.Println("[*] Cookie session enabled: requests will carry the WAF's session cookies forward and renew them periodically")
+			}
+		} else {
+			fmt.Println("[*] No WAF detected")
+		}
+	}
+
 	// Initialize scan summary for report generation
 	scanSummary := &scanner.ScanSummary{
 		TargetURL:            config.URL,
@@ -119,12 +220,25 @@ func main() {
 		StartTime:            time.Now(),
 		BrowserVerifyEnabled: config.BrowserVerify,
 		Results:              []scanner.ScanResult{},
+		WAFInfo:              waf,
 	}
 
-	// Scan each parameter
-	for _, param := range config.Params {
-		paramResults := scanParameter(config, param, payloads)
-		scanSummary.Results = append(scanSummary.Results, paramResults...)
+	checkBlocked := scanner.DefaultCheckBlockFunc(waf)
+
+	// Cancelled on Ctrl-C so a concurrent scan's in-flight requests abort
+	// instead of the process hanging until they time out on their own
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch {
+	case requestTemplate != nil:
+		scanSummary.Results = append(scanSummary.Results, runRequestTemplateScan(ctx, config, requestTemplate, payloads, checkBlocked, session)...)
+	case config.ScanMode == "stored":
+		scanSummary.Results = append(scanSummary.Results, runStoredScan(config)...)
+	case config.ScanMode == "dom":
+		scanSummary.Results = append(scanSummary.Results, runDOMScan(config, payloads)...)
+	default:
+		scanSummary.Results = append(scanSummary.Results, runReflectedScan(ctx, config, payloads, checkBlocked, session)...)
 	}
 
 	// Update summary counts
@@ -138,9 +252,17 @@ func main() {
 			}
 		case scanner.EscapedReflection:
 			scanSummary.EscapedCount++
+		case scanner.Blocked:
+			scanSummary.BlockedCount++
 		}
 	}
 
+	// report-sink errors are ignored here: ParseSink already validated
+	// config.ReportSink in parseArgs, so the only failures left are
+	// delivery failures the caller logs and keeps going on, same as the
+	// report-generation errors below
+	sink, _ := report.ParseSink(config.ReportSink)
+
 	// Generate report if requested
 	if config.GenerateReport {
 		fmt.Println("\n[*] Generating CSV report...")
@@ -151,19 +273,25 @@ func main() {
 			fmt.Fprintf(os.Stderr, "[!] Failed to save CSV report: %v\n", err)
 		} else {
 			fmt.Printf("[+] CSV Report saved to: %s\n", config.CSVOutput)
+			deliverReport(sink, config.CSVOutput)
 		}
+	}
 
-		// Sync to Google Drive using rclone
-		fmt.Println("\n[*] Syncing reports to Google Drive...")
-		cmd := exec.Command("rclone", "sync", "./outputs", "gdrive:csv-data")
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	if config.JSONReport {
+		if err := scanner.SaveJSONReport(scanSummary, config.JSONOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "[!] Failed to save JSON report: %v\n", err)
+		} else {
+			fmt.Printf("[+] JSON Report saved to: %s\n", config.JSONOutput)
+			deliverReport(sink, config.JSONOutput)
+		}
+	}
 
-		if err := cmd.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Failed to sync to Google Drive: %v\n", err)
-			fmt.Fprintf(os.Stderr, "[!] Make sure rclone is configured (run: rclone config)\n")
+	if config.SARIFReport {
+		if err := scanner.SaveSARIFReport(scanSummary, config.SARIFOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "[!] Failed to save SARIF report: %v\n", err)
 		} else {
-			fmt.Printf("[+] Reports synced to Google Drive: gdrive:csv-data\n")
+			fmt.Printf("[+] SARIF Report saved to: %s\n", config.SARIFOutput)
+			deliverReport(sink, config.SARIFOutput)
 		}
 	}
 
@@ -186,6 +314,35 @@ func parseArgs() (*Config, error) {
 	chromeDriver := flag.String("chrome-driver", "chromedriver", "Path to ChromeDriver executable")
 	generateReport := flag.Bool("report", false, "Generate CSV report with visualizations")
 	csvOutput := flag.String("csv-output", "", "Custom output file path for CSV report (default: auto-generated in outputs/)")
+	jsonReport := flag.Bool("json-report", false, "Generate a JSON report with the full scan summary")
+	jsonOutput := flag.String("json-output", "", "Custom output file path for JSON report (default: auto-generated in outputs/)")
+	sarifReport := flag.Bool("sarif-report", false, "Generate a SARIF 2.1.0 report for GitHub/GitLab/DefectDojo ingestion")
+	sarifOutput := flag.String("sarif-output", "", "Custom output file path for SARIF report (default: auto-generated in outputs/)")
+	wafDetect := flag.Bool("waf-detect", true, "Probe the target for a WAF before scanning and flag blocked payloads separately")
+	scanMode := flag.String("scan-mode", "reflected", "Scan mode: reflected, stored, or dom")
+	crawl := flag.Bool("crawl", false, "BFS-crawl from --url to discover pages/forms for the stored scan mode (dom mode tests --url only)")
+	maxCrawlDepth := flag.Int("max-crawl-depth", 2, "Maximum crawl depth when --crawl is enabled")
+	crawlCrossOrigin := flag.Bool("crawl-cross-origin", false, "Allow --crawl to follow links off the seed URL's host instead of staying same-origin")
+	crawlRespectRobots := flag.Bool("crawl-respect-robots", false, "Fetch the seed URL's robots.txt and skip any path it disallows for --crawl")
+	submitURL := flag.String("submit-url", "", "Stored XSS mode: submit the marker payload here instead of crawling (requires --verify-url)")
+	verifyURL := flag.String("verify-url", "", "Stored XSS mode: check this URL for the marker instead of crawling (requires --submit-url)")
+	workers := flag.Int("workers", 1, "Number of concurrent worker goroutines for the reflected scan")
+	rps := flag.Float64("rps", 5, "Max requests per second against the target (token bucket)")
+	browserWorkers := flag.Int("browser-workers", 1, "Number of pooled browser instances for concurrent --browser-verify")
+	checkpointPath := flag.String("checkpoint", "", "Save scan progress to this file after each completed job")
+	resumePath := flag.String("resume", "", "Resume a reflected scan from a previously saved --checkpoint file")
+	showProgress := flag.Bool("progress", false, "Show a live TTY progress bar (jobs done/total, req/s, verified count)")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics at this address (e.g. :9090)")
+	reportSink := flag.String("report-sink", "", "Where to deliver generated reports: local (default), stdout, rclone:<remote>, s3://bucket/prefix, gcs://bucket/prefix, or webhook:<url>")
+	reportFormat := flag.String("report-format", "", "Comma-separated report formats to generate: csv,json,sarif (shorthand for --report/--json-report/--sarif-report together)")
+	requestFile := flag.String("request-file", "", "Path to a raw HTTP request file (sqlmap -r / Burp 'Copy as request' style) with a marker for payload injection")
+	marker := flag.String("marker", scanner.DefaultMarker, "Marker string --request-file looks for to know where to substitute each payload")
+	scheme := flag.String("scheme", "", "URL scheme (http or https) to use with --request-file, since a raw request file carries no scheme; if omitted it is taken from --url, and one of the two must be given")
+	var headers repeatableFlag
+	flag.Var(&headers, "header", "Extra header to add/override, as \"Name: Value\" (repeatable)")
+	cookie := flag.String("cookie", "", "Cookie header to add/override, e.g. \"session=abc; theme=dark\"")
+	contexts := flag.String("contexts", "", "Comma-separated reflection contexts to limit the scan to, e.g. html-body,attr-dq,js-string: a parameter whose probe lands in another context is skipped (default: all)")
+	bypassFlag := flag.String("bypass", "", "Comma-separated WAF/filter bypass encodings to also try per payload: each one alone, plus (if more than one) all of them chained together in order, e.g. url2x,entity-hex,case (or \"all\")")
 
 	// Custom usage message
 	flag.Usage = func() {
@@ -214,22 +371,95 @@ func parseArgs() (*Config, error) {
 		fmt.Fprintf(os.Stderr, "  --report\n")
 		fmt.Fprintf(os.Stderr, "        Generate CSV report with visualizations (auto-timestamped in outputs/)\n")
 		fmt.Fprintf(os.Stderr, "  --csv-output string\n")
-		fmt.Fprintf(os.Stderr, "        Custom output file path for CSV report (default: auto-generated)\n\n")
+		fmt.Fprintf(os.Stderr, "        Custom output file path for CSV report (default: auto-generated)\n")
+		fmt.Fprintf(os.Stderr, "  --json-report\n")
+		fmt.Fprintf(os.Stderr, "        Generate a JSON report with the full scan summary (auto-timestamped in outputs/)\n")
+		fmt.Fprintf(os.Stderr, "  --json-output string\n")
+		fmt.Fprintf(os.Stderr, "        Custom output file path for JSON report (default: auto-generated)\n")
+		fmt.Fprintf(os.Stderr, "  --sarif-report\n")
+		fmt.Fprintf(os.Stderr, "        Generate a SARIF 2.1.0 report for CI ingestion (auto-timestamped in outputs/)\n")
+		fmt.Fprintf(os.Stderr, "  --sarif-output string\n")
+		fmt.Fprintf(os.Stderr, "        Custom output file path for SARIF report (default: auto-generated)\n")
+		fmt.Fprintf(os.Stderr, "  --waf-detect\n")
+		fmt.Fprintf(os.Stderr, "        Probe the target for a WAF before scanning (default: true)\n")
+		fmt.Fprintf(os.Stderr, "  --scan-mode string\n")
+		fmt.Fprintf(os.Stderr, "        Scan mode: reflected, stored, or dom (default: reflected)\n")
+		fmt.Fprintf(os.Stderr, "  --crawl\n")
+		fmt.Fprintf(os.Stderr, "        BFS-crawl from --url to discover pages/forms for stored mode (dom mode tests --url only)\n")
+		fmt.Fprintf(os.Stderr, "  --max-crawl-depth int\n")
+		fmt.Fprintf(os.Stderr, "        Maximum crawl depth when --crawl is enabled (default: 2)\n")
+		fmt.Fprintf(os.Stderr, "  --crawl-cross-origin\n")
+		fmt.Fprintf(os.Stderr, "        Allow --crawl to follow links off the seed URL's host (default: false, same-origin only)\n")
+		fmt.Fprintf(os.Stderr, "  --crawl-respect-robots\n")
+		fmt.Fprintf(os.Stderr, "        Fetch robots.txt and skip disallowed paths while --crawl is enabled (default: false)\n")
+		fmt.Fprintf(os.Stderr, "  --submit-url string / --verify-url string\n")
+		fmt.Fprintf(os.Stderr, "        Stored XSS mode: submit to one endpoint, verify on another, instead of crawling\n")
+		fmt.Fprintf(os.Stderr, "  --workers int\n")
+		fmt.Fprintf(os.Stderr, "        Concurrent worker goroutines for the reflected scan (default: 1)\n")
+		fmt.Fprintf(os.Stderr, "  --rps float\n")
+		fmt.Fprintf(os.Stderr, "        Max requests per second against the target (default: 5)\n")
+		fmt.Fprintf(os.Stderr, "  --browser-workers int\n")
+		fmt.Fprintf(os.Stderr, "        Pooled browser instances for concurrent --browser-verify (default: 1)\n")
+		fmt.Fprintf(os.Stderr, "  --checkpoint string\n")
+		fmt.Fprintf(os.Stderr, "        Save scan progress to this file after each completed job\n")
+		fmt.Fprintf(os.Stderr, "  --resume string\n")
+		fmt.Fprintf(os.Stderr, "        Resume a reflected scan from a previously saved --checkpoint file\n")
+		fmt.Fprintf(os.Stderr, "  --progress\n")
+		fmt.Fprintf(os.Stderr, "        Show a live TTY progress bar (jobs done/total, req/s, verified count)\n")
+		fmt.Fprintf(os.Stderr, "  --metrics-addr string\n")
+		fmt.Fprintf(os.Stderr, "        Serve Prometheus metrics at this address (e.g. :9090)\n")
+		fmt.Fprintf(os.Stderr, "  --report-sink string\n")
+		fmt.Fprintf(os.Stderr, "        Where to deliver generated reports: local (default), stdout, rclone:<remote>,\n")
+		fmt.Fprintf(os.Stderr, "        s3://bucket/prefix, gcs://bucket/prefix, or webhook:<url>\n")
+		fmt.Fprintf(os.Stderr, "  --report-format string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated report formats to generate: csv,json,sarif\n")
+		fmt.Fprintf(os.Stderr, "        (shorthand for --report/--json-report/--sarif-report together)\n")
+		fmt.Fprintf(os.Stderr, "  --request-file string\n")
+		fmt.Fprintf(os.Stderr, "        Raw HTTP request file with a marker for payload injection (use instead of --url/--params)\n")
+		fmt.Fprintf(os.Stderr, "  --marker string\n")
+		fmt.Fprintf(os.Stderr, "        Marker --request-file substitutes each payload for (default: {INJECT})\n")
+		fmt.Fprintf(os.Stderr, "  --scheme string\n")
+		fmt.Fprintf(os.Stderr, "        URL scheme (http or https) to use with --request-file; if omitted it is taken\n")
+		fmt.Fprintf(os.Stderr, "        from --url, and one of the two must be given\n")
+		fmt.Fprintf(os.Stderr, "  --header string\n")
+		fmt.Fprintf(os.Stderr, "        Extra header to add/override, as \"Name: Value\" (repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --cookie string\n")
+		fmt.Fprintf(os.Stderr, "        Cookie header to add/override\n")
+		fmt.Fprintf(os.Stderr, "  --contexts string\n")
+		fmt.Fprintf(os.Stderr, "        Limit the scan to these reflection contexts, e.g. html-body,attr-dq,js-string;\n")
+		fmt.Fprintf(os.Stderr, "        a parameter whose probe lands in another context is skipped\n")
+		fmt.Fprintf(os.Stderr, "  --bypass string\n")
+		fmt.Fprintf(os.Stderr, "        Also try these WAF/filter bypass encodings per payload: each alone, plus (if more\n")
+		fmt.Fprintf(os.Stderr, "        than one) the full chain composed in order, e.g. url2x,entity-hex,case (or \"all\")\n\n")
 		fmt.Fprintf(os.Stderr, "Example:\n")
 		fmt.Fprintf(os.Stderr, "  xsspect --url https://example.com/search --params q,name --method GET\n")
 		fmt.Fprintf(os.Stderr, "  xsspect --url https://example.com/search --params q --report\n")
-		fmt.Fprintf(os.Stderr, "  xsspect --url https://example.com/search --params q --report --csv-output results.csv\n\n")
+		fmt.Fprintf(os.Stderr, "  xsspect --url https://example.com/search --params q --report-format json --report-sink stdout\n")
+		fmt.Fprintf(os.Stderr, "  xsspect --url https://example.com/search --params q --report --csv-output results.csv\n")
+		fmt.Fprintf(os.Stderr, "  xsspect --request-file req.txt --scheme https --header \"Authorization: Bearer xyz\"\n")
+		fmt.Fprintf(os.Stderr, "  xsspect --url https://example.com/search --params q --bypass url2x,entity-hex,case\n")
+		fmt.Fprintf(os.Stderr, "      (tries url2x alone, entity-hex alone, case alone, and url2x->entity-hex->case chained)\n\n")
 	}
 
 	flag.Parse()
 
-	// Validate required arguments
-	if *url == "" {
-		return nil, fmt.Errorf("--url is required")
+	// Validate required arguments. --request-file stands in for --url +
+	// --params: the marker inside the request file is the injection point,
+	// so there's no separate parameter list to test
+	if *requestFile == "" {
+		if *url == "" {
+			return nil, fmt.Errorf("--url is required (unless --request-file is used)")
+		}
+
+		if *params == "" {
+			return nil, fmt.Errorf("--params is required (unless --request-file is used)")
+		}
+	} else if *scheme == "" && *url == "" {
+		return nil, fmt.Errorf("--request-file needs either --scheme or --url to know which scheme to connect with (a raw request file carries no scheme)")
 	}
 
-	if *params == "" {
-		return nil, fmt.Errorf("--params is required")
+	if *scheme != "" && *scheme != "http" && *scheme != "https" {
+		return nil, fmt.Errorf("--scheme must be \"http\" or \"https\", got %q", *scheme)
 	}
 
 	// Populate config
@@ -242,24 +472,123 @@ func parseArgs() (*Config, error) {
 	config.BrowserVerify = *browserVerify
 	config.ChromeDriverPath = *chromeDriver
 	config.GenerateReport = *generateReport
+	config.JSONReport = *jsonReport
+	config.SARIFReport = *sarifReport
+	config.WAFDetect = *wafDetect
+	config.ScanMode = strings.ToLower(*scanMode)
+	config.Crawl = *crawl
+	config.MaxCrawlDepth = *maxCrawlDepth
+	config.CrawlCrossOrigin = *crawlCrossOrigin
+	config.CrawlRespectRobots = *crawlRespectRobots
+	config.SubmitURL = *submitURL
+	config.VerifyURL = *verifyURL
+	config.Workers = *workers
+	config.RPS = *rps
+	config.BrowserWorkers = *browserWorkers
+	config.CheckpointPath = *checkpointPath
+	config.ResumePath = *resumePath
+	config.ShowProgress = *showProgress
+	config.MetricsAddr = *metricsAddr
+	config.ReportSink = *reportSink
+	config.RequestFile = *requestFile
+	config.Marker = *marker
+	config.Scheme = *scheme
+	config.Headers = []string(headers)
+	config.Cookie = *cookie
+
+	if _, err := report.ParseSink(config.ReportSink); err != nil {
+		return nil, err
+	}
 
-	// Generate timestamped filename if report generation is enabled and no custom path provided
-	if config.GenerateReport {
+	if *contexts != "" {
+		for _, name := range strings.Split(*contexts, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			ctx, err := scanner.ParseContext(name)
+			if err != nil {
+				return nil, err
+			}
+			config.Contexts = append(config.Contexts, ctx)
+		}
+	}
+
+	if *bypassFlag != "" {
+		names, err := bypass.Parse(*bypassFlag)
+		if err != nil {
+			return nil, err
+		}
+		config.Bypass = names
+	}
+
+	// --report-format is a comma-list shorthand for enabling the
+	// individual --report/--json-report/--sarif-report flags together, for
+	// callers that want e.g. --report-format csv,sarif in one go
+	if *reportFormat != "" {
+		for _, format := range strings.Split(*reportFormat, ",") {
+			switch strings.ToLower(strings.TrimSpace(format)) {
+			case "csv":
+				config.GenerateReport = true
+			case "json":
+				config.JSONReport = true
+			case "sarif":
+				config.SARIFReport = true
+			case "":
+				// tolerate trailing commas
+			default:
+				return nil, fmt.Errorf("invalid --report-format %q (want csv, json, and/or sarif)", format)
+			}
+		}
+	}
+
+	validModes := map[string]bool{"reflected": true, "stored": true, "dom": true}
+	if !validModes[config.ScanMode] {
+		return nil, fmt.Errorf("invalid --scan-mode: %s (must be reflected, stored, or dom)", *scanMode)
+	}
+
+	if config.Workers < 1 {
+		return nil, fmt.Errorf("--workers must be at least 1")
+	}
+	if config.BrowserWorkers < 1 {
+		return nil, fmt.Errorf("--browser-workers must be at least 1")
+	}
+
+	if (config.SubmitURL == "") != (config.VerifyURL == "") {
+		return nil, fmt.Errorf("--submit-url and --verify-url must be used together")
+	}
+
+	// Generate timestamped filenames for any enabled report that wasn't given
+	// a custom output path
+	if config.GenerateReport || config.JSONReport || config.SARIFReport {
 		// Create outputs directory if it doesn't exist
 		if err := os.MkdirAll("outputs", 0755); err != nil {
 			return nil, fmt.Errorf("failed to create outputs directory: %w", err)
 		}
 
-		// Generate timestamp-based filename
 		timestamp := time.Now().Format("20060102_150405")
 
-		if *csvOutput == "" {
+		if config.GenerateReport && *csvOutput == "" {
 			config.CSVOutput = fmt.Sprintf("outputs/xsspect_report_%s.csv", timestamp)
 		} else {
 			config.CSVOutput = *csvOutput
 		}
+
+		if config.JSONReport && *jsonOutput == "" {
+			config.JSONOutput = fmt.Sprintf("outputs/xsspect_report_%s.json", timestamp)
+		} else {
+			config.JSONOutput = *jsonOutput
+		}
+
+		if config.SARIFReport && *sarifOutput == "" {
+			config.SARIFOutput = fmt.Sprintf("outputs/xsspect_report_%s.sarif", timestamp)
+		} else {
+			config.SARIFOutput = *sarifOutput
+		}
 	} else {
 		config.CSVOutput = *csvOutput
+		config.JSONOutput = *jsonOutput
+		config.SARIFOutput = *sarifOutput
 	}
 
 	// Validate that only one payload source is specified
@@ -344,160 +673,722 @@ func loadPayloadsFromFile(filePath string) ([]string, error) {
 	return payloads, nil
 }
 
-// scanParameter tests a single parameter with all payloads
-func scanParameter(config *Config, param string, payloads []string) []scanner.ScanResult {
-	fmt.Printf("[*] Testing param: %s\n", param)
+// runReflectedScan tests every (parameter, payload) pair through a
+// ScanEngine worker pool instead of looping serially, so --workers > 1
+// fans requests out across goroutines under a shared rate limit. It wires
+// up the optional browser pool, checkpoint/resume, TTY progress bar and
+// Prometheus endpoint, then prints the same per-parameter summary the old
+// serial loop did. ctx is cancelled on Ctrl-C so in-flight requests abort
+// instead of the process hanging until they time out on their own.
+func runReflectedScan(ctx context.Context, config *Config, payloads []string, checkBlocked scanner.CheckBlockFunc, session *scanner.CookieSession) []scanner.ScanResult {
+	mutator := scanner.NewPayloadMutator()
+
+	// Probe each parameter once with a cheap benign marker before touching
+	// the wordlist: if it lands somewhere DetectContext can place, test only
+	// the tailored payload set for that context instead of the full
+	// wordlist, the standard efficient approach mature scanners use. A
+	// parameter that doesn't confirm a context (escaped, blocked, not
+	// reflected) still gets the full wordlist, same as before. A parameter
+	// whose confirmed context is excluded by --contexts is skipped outright
+	// rather than falling back to the full wordlist — --contexts is meant to
+	// narrow a scan, and testing a context the user explicitly excluded
+	// would do the opposite.
+	jobs := make([]scanner.Job, 0, len(config.Params)*len(payloads))
+	for _, param := range config.Params {
+		paramPayloads := payloads
+		if probedCtx := probeContext(ctx, config, param, session); probedCtx != scanner.ContextUnknown {
+			if !contextAllowed(config, probedCtx) {
+				fmt.Printf("[*] Probe for param '%s' landed in %s context, which --contexts excludes: skipping\n", param, probedCtx.String())
+				continue
+			}
+			if tailored := mutator.MutationsForContext(probedCtx); len(tailored) > 0 {
+				fmt.Printf("[*] Probe for param '%s' landed in %s context: testing %d tailored payload(s) instead of the full wordlist\n", param, probedCtx.String(), len(tailored))
+				paramPayloads = tailored
+			}
+		}
+
+		for _, payload := range paramPayloads {
+			jobs = append(jobs, scanner.Job{Parameter: param, Payload: payload})
+			jobs = append(jobs, bypassJobs(param, payload, config.Bypass)...)
+		}
+	}
 
 	var results []scanner.ScanResult
+	var priorCompleted []scanner.Job
+	if config.ResumePath != "" {
+		checkpoint, err := scanner.LoadCheckpoint(config.ResumePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] Failed to load checkpoint %s: %v\n", config.ResumePath, err)
+		} else {
+			done := checkpoint.CompletedSet()
+			remaining := jobs[:0]
+			for _, job := range jobs {
+				if !done[job] {
+					remaining = append(remaining, job)
+				}
+			}
+			fmt.Printf("[*] Resuming %s: %d/%d jobs already completed\n", config.URL, len(done), len(jobs))
+			jobs = remaining
+			results = append(results, checkpoint.ResultsSoFar...)
+			priorCompleted = checkpoint.CompletedJobs
+		}
+	}
 
-	// Initialize browser verifier if enabled
-	var browserVerifier *scanner.BrowserVerifier
+	var browserPool *scanner.BrowserPool
 	if config.BrowserVerify {
-		browserConfig := scanner.BrowserConfig{
+		pool, err := scanner.NewBrowserPool(config.BrowserWorkers, scanner.BrowserConfig{
 			ChromeDriverPath: config.ChromeDriverPath,
 			Headless:         true,
-		}
-
-		bv, err := scanner.NewBrowserVerifier(browserConfig)
+		})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Failed to initialize browser: %v\n", err)
-			fmt.Fprintf(os.Stderr, "[!] Make sure ChromeDriver is installed and in PATH\n")
+			fmt.Fprintf(os.Stderr, "[!] Failed to start browser pool: %v\n", err)
 			fmt.Fprintf(os.Stderr, "[!] Continuing with static analysis only...\n\n")
 		} else {
-			err = bv.Start()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "[!] Failed to start browser: %v\n", err)
-				fmt.Fprintf(os.Stderr, "[!] Continuing with static analysis only...\n\n")
-				browserVerifier = nil
-			} else {
-				browserVerifier = bv
-				defer browserVerifier.Close()
-				fmt.Printf("[*] Browser verification enabled (headless mode)\n\n")
+			browserPool = pool
+			defer browserPool.Close()
+			fmt.Printf("[*] Browser pool started (%d instance(s))\n", config.BrowserWorkers)
+		}
+	}
+
+	engine := scanner.NewScanEngine(config.Workers, config.RPS)
+
+	if config.MetricsAddr != "" {
+		go func() {
+			if err := scanner.ServeMetrics(config.MetricsAddr, engine); err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Metrics server stopped: %v\n", err)
+			}
+		}()
+		fmt.Printf("[*] Metrics available at http://%s/metrics\n", config.MetricsAddr)
+	}
+
+	fmt.Printf("[*] Testing %d parameter(s) across %d job(s) using %d worker(s) at %.1f req/s\n\n",
+		len(config.Params), len(jobs), config.Workers, config.RPS)
+
+	var progressDone chan struct{}
+	if config.ShowProgress {
+		progressDone = make(chan struct{})
+		go showProgress(engine, progressDone)
+	}
+
+	var stopMu sync.Mutex
+	stoppedParams := make(map[string]bool)
+	process := buildReflectedProcessFunc(config, checkBlocked, session, browserPool, &stopMu, stoppedParams)
+
+	var resultsMu sync.Mutex
+	completed := append(make([]scanner.Job, 0, len(priorCompleted)+len(jobs)), priorCompleted...)
+	pending := append([]scanner.Job{}, jobs...)
+
+	engine.Run(ctx, jobs, process, func(job scanner.Job, jobResults []scanner.ScanResult) {
+		resultsMu.Lock()
+		defer resultsMu.Unlock()
+
+		results = append(results, jobResults...)
+		completed = append(completed, job)
+		for i, p := range pending {
+			if p == job {
+				pending = append(pending[:i], pending[i+1:]...)
+				break
 			}
 		}
+
+		if config.StopOnHit {
+			for _, r := range jobResults {
+				if r.ReflectionType == scanner.RawReflection {
+					stopMu.Lock()
+					stoppedParams[job.Parameter] = true
+					stopMu.Unlock()
+					break
+				}
+			}
+		}
+
+		if config.CheckpointPath != "" {
+			state := &scanner.CheckpointState{
+				Target:        config.URL,
+				CompletedJobs: append([]scanner.Job{}, completed...),
+				PendingJobs:   append([]scanner.Job{}, pending...),
+				ResultsSoFar:  append([]scanner.ScanResult{}, results...),
+			}
+			if err := scanner.SaveCheckpoint(state, config.CheckpointPath); err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Failed to save checkpoint: %v\n", err)
+			}
+		}
+	})
+
+	if config.ShowProgress {
+		close(progressDone)
+		fmt.Println()
 	}
 
-	rawHitCount := 0
-	escapedHitCount := 0
-	verifiedHitCount := 0
+	printParamSummaries(config, results)
 
+	return results
+}
+
+// runRequestTemplateScan drives a --request-file scan: instead of building a
+// URL from --url/--params, it substitutes each payload for config.Marker
+// inside the parsed RequestTemplate (URL, headers and body alike) and sends
+// the resulting raw request. It reuses the same ScanEngine worker pool as
+// runReflectedScan so --workers/--rps/--progress all keep working, but skips
+// browser verification, checkpoint/resume and context-tailored mutation,
+// since there's no single "parameter" to mutate against — those stay
+// specific to --url/--params scans for now.
+func runRequestTemplateScan(ctx context.Context, config *Config, tmpl *scanner.RequestTemplate, payloads []string, checkBlocked scanner.CheckBlockFunc, session *scanner.CookieSession) []scanner.ScanResult {
+	jobs := make([]scanner.Job, 0, len(payloads))
 	for _, payload := range payloads {
-		// Build URL with injected payload
-		testURL, err := scanner.BuildRequestURL(config.URL, param, payload)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "[!] Error building URL: %v\n", err)
-			continue
+		jobs = append(jobs, scanner.Job{Parameter: config.Marker, Payload: payload})
+	}
+
+	scheme := "https"
+	if config.URL != "" {
+		if parsed, err := url.Parse(config.URL); err == nil && parsed.Scheme != "" {
+			scheme = parsed.Scheme
 		}
+	}
+	if config.Scheme != "" {
+		scheme = config.Scheme
+	}
+
+	engine := scanner.NewScanEngine(config.Workers, config.RPS)
+
+	fmt.Printf("[*] Testing %d payload(s) against the request file's marker using %d worker(s) at %.1f req/s\n\n",
+		len(payloads), config.Workers, config.RPS)
 
-		// Send HTTP request
-		reqConfig := scanner.RequestConfig{
-			URL:    testURL,
-			Method: config.Method,
+	var progressDone chan struct{}
+	if config.ShowProgress {
+		progressDone = make(chan struct{})
+		go showProgress(engine, progressDone)
+	}
+
+	process := func(ctx context.Context, job scanner.Job) ([]scanner.ScanResult, bool) {
+		reqConfig, err := tmpl.Inject(scheme, config.Marker, job.Payload)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] Failed to build request from template: %v\n", err)
+			return nil, false
+		}
+		reqConfig.Ctx = ctx
+		if session != nil {
+			reqConfig.Jar = session.Jar
 		}
 
 		result := scanner.SendRequest(reqConfig)
+		if session != nil {
+			session.Track()
+		}
+		if result.Error != nil {
+			fmt.Fprintf(os.Stderr, "[!] Request failed for %s (scheme %q): %v\n", reqConfig.URL, scheme, result.Error)
+			return nil, false
+		}
+
+		throttled := scanner.IsThrottleStatus(result.StatusCode)
+
+		if checkBlocked != nil && checkBlocked(result) {
+			blocked := scanner.ScanResult{Parameter: job.Parameter, Payload: job.Payload, ReflectionType: scanner.Blocked}
+			printJobResult(config, job, blocked)
+			return []scanner.ScanResult{blocked}, throttled
+		}
+
+		analysis := scanner.AnalyzeResponse(result.ResponseBody, job.Payload, job.Parameter)
+
+		var reflectionContext scanner.ReflectionContext
+		if analysis.Type == scanner.RawReflection {
+			reflectionContext = scanner.DetectContext(result.ResponseBody, job.Payload)
+		}
+
+		scanResult := scanner.ScanResult{
+			Parameter:      job.Parameter,
+			Payload:        job.Payload,
+			ReflectionType: analysis.Type,
+			Context:        reflectionContext,
+		}
+		printJobResult(config, job, scanResult)
+		return []scanner.ScanResult{scanResult}, throttled
+	}
+
+	var results []scanner.ScanResult
+	var resultsMu sync.Mutex
+	engine.Run(ctx, jobs, process, func(job scanner.Job, jobResults []scanner.ScanResult) {
+		resultsMu.Lock()
+		defer resultsMu.Unlock()
+		results = append(results, jobResults...)
+	})
+
+	if config.ShowProgress {
+		close(progressDone)
+		fmt.Println()
+	}
+
+	printParamSummaries(config, results)
 
-		// Handle request errors
+	return results
+}
+
+// contextAllowed reports whether ctx is one of the --contexts the user
+// constrained the scan to, or whether --contexts wasn't set at all
+func contextAllowed(config *Config, ctx scanner.ReflectionContext) bool {
+	if len(config.Contexts) == 0 {
+		return true
+	}
+	for _, allowed := range config.Contexts {
+		if allowed == ctx {
+			return true
+		}
+	}
+	return false
+}
+
+// probeContext sends scanner.BenignProbe once for param and, if it reflects
+// unescaped, returns the markup context it landed in so runReflectedScan can
+// generate a tailored payload set instead of firing the entire wordlist
+// blindly. Returns scanner.ContextUnknown if the probe didn't confirm a
+// context (escaped, blocked, or not reflected at all), in which case the
+// caller falls back to the full wordlist.
+func probeContext(ctx context.Context, config *Config, param string, session *scanner.CookieSession) scanner.ReflectionContext {
+	testURL, err := scanner.BuildRequestURL(config.URL, param, scanner.BenignProbe)
+	if err != nil {
+		return scanner.ContextUnknown
+	}
+
+	reqConfig := scanner.RequestConfig{URL: testURL, Method: config.Method, Ctx: ctx}
+	if session != nil {
+		reqConfig.Jar = session.Jar
+	}
+
+	result := scanner.SendRequest(reqConfig)
+	if session != nil {
+		session.Track()
+	}
+	if result.Error != nil {
+		return scanner.ContextUnknown
+	}
+
+	analysis := scanner.AnalyzeResponse(result.ResponseBody, scanner.BenignProbe, param)
+	if analysis.Type != scanner.RawReflection {
+		return scanner.ContextUnknown
+	}
+
+	return scanner.DetectContext(result.ResponseBody, scanner.BenignProbe)
+}
+
+// bypassJobs builds the extra --bypass variants for one (param, payload)
+// pair: one independent variant per selected mutator, so a defender can see
+// which single encoding got through, plus — when more than one mutator is
+// selected — one additional job chaining all of them together in the
+// selected order, tagged with the full comma-joined sequence so
+// ScanResult.Mutations records the actual chain that was applied.
+func bypassJobs(param, payload string, names []bypass.Name) []scanner.Job {
+	if len(names) == 0 {
+		return nil
+	}
+
+	jobs := make([]scanner.Job, 0, len(names)+1)
+	for _, name := range names {
+		jobs = append(jobs, scanner.Job{Parameter: param, Payload: bypass.Apply(payload, name), Mutation: string(name), BasePayload: payload})
+	}
+
+	if len(names) > 1 {
+		chainNames := make([]string, len(names))
+		for i, name := range names {
+			chainNames[i] = string(name)
+		}
+		jobs = append(jobs, scanner.Job{
+			Parameter:   param,
+			Payload:     bypass.Chain(payload, names),
+			Mutation:    strings.Join(chainNames, ","),
+			BasePayload: payload,
+		})
+	}
+
+	return jobs
+}
+
+// bypassConfirmed decodes a --bypass job's mutation chain back off its
+// Payload and checks whether that recovers job.BasePayload. It decodes
+// job.Payload as sent, not text taken from the response, so it confirms
+// that the chain is reversible; it does not tell a server that decoded the
+// variant apart from one that echoed the encoded text back unchanged.
+func bypassConfirmed(job scanner.Job) bool {
+	names := make([]bypass.Name, 0, strings.Count(job.Mutation, ",")+1)
+	for _, name := range strings.Split(job.Mutation, ",") {
+		names = append(names, bypass.Name(name))
+	}
+
+	decoded, ok := bypass.DecodeChain(job.Payload, names)
+	if !ok {
+		return false
+	}
+	return strings.Contains(strings.ToLower(decoded), strings.ToLower(job.BasePayload))
+}
+
+// jobMutations splits a Job's comma-joined Mutation field back into the
+// slice ScanResult.Mutations expects, or nil if no --bypass variant produced
+// this job's payload
+func jobMutations(mutation string) []string {
+	if mutation == "" {
+		return nil
+	}
+	return strings.Split(mutation, ",")
+}
+
+// buildReflectedProcessFunc returns the per-job worker function the
+// ScanEngine calls concurrently: build the request, classify the
+// reflection, and verify in a pooled browser if enabled. Context-tailored
+// payloads are decided up front by probeContext in runReflectedScan, so this
+// just runs whichever job (wordlist or tailored) it's handed like any other.
+// session, when a WAF was detected, carries cookies across every job and
+// periodically renews them (see scanner.CookieSession) instead of each
+// payload going out on a fresh, cookie-less connection.
+func buildReflectedProcessFunc(config *Config, checkBlocked scanner.CheckBlockFunc, session *scanner.CookieSession, browserPool *scanner.BrowserPool, stopMu *sync.Mutex, stoppedParams map[string]bool) scanner.ProcessFunc {
+	return func(ctx context.Context, job scanner.Job) ([]scanner.ScanResult, bool) {
+		if config.StopOnHit {
+			stopMu.Lock()
+			stopped := stoppedParams[job.Parameter]
+			stopMu.Unlock()
+			if stopped {
+				return nil, false
+			}
+		}
+
+		testURL, err := scanner.BuildRequestURL(config.URL, job.Parameter, job.Payload)
+		if err != nil {
+			return nil, false
+		}
+
+		reqConfig := scanner.RequestConfig{URL: testURL, Method: config.Method, Ctx: ctx}
+		if session != nil {
+			reqConfig.Jar = session.Jar
+		}
+
+		result := scanner.SendRequest(reqConfig)
+		if session != nil {
+			session.Track()
+		}
 		if result.Error != nil {
-			// Only print errors that aren't just timeouts or common network issues
-			// (to keep output clean)
-			continue
+			return nil, false
 		}
 
-		// Analyze response
-		analysis := scanner.AnalyzeResponse(result.ResponseBody, payload, param)
+		throttled := scanner.IsThrottleStatus(result.StatusCode)
+
+		// A WAF block looks like "no reflection" to the analyzer unless we
+		// check for it first, so distinguish it before classifying reflection
+		if checkBlocked != nil && checkBlocked(result) {
+			blocked := scanner.ScanResult{Parameter: job.Parameter, Payload: job.Payload, ReflectionType: scanner.Blocked}
+			printJobResult(config, job, blocked)
+			return []scanner.ScanResult{blocked}, throttled
+		}
+
+		analysis := scanner.AnalyzeResponse(result.ResponseBody, job.Payload, job.Parameter)
+
+		var browserVerifier *scanner.BrowserVerifier
+		if browserPool != nil {
+			browserVerifier = browserPool.Get()
+		}
 
-		// If browser verification is enabled and we found RAW reflection, verify execution
 		browserVerified := false
 		xssEventType := ""
-		if config.BrowserVerify && browserVerifier != nil && analysis.Type == scanner.RawReflection {
-			detected, eventType, err := browserVerifier.VerifyWithRetry(testURL, 1)
-			if err != nil {
-				// Verification failed, but we still report it as RAW (static analysis found it)
-				fmt.Printf("[!] Browser verification failed: %v\n", err)
-			} else if detected {
+		var sinkHits []scanner.SinkHit
+		if browserVerifier != nil && analysis.Type == scanner.RawReflection {
+			hits, err := browserVerifier.VerifyWithRetry(testURL, 1)
+			if err == nil && len(hits) > 0 {
 				browserVerified = true
-				xssEventType = eventType
-				verifiedHitCount++
+				sinkHits = hits
+				xssEventType = hits[0].Sink
 			}
 		}
 
-		// Store result for report generation
+		// job.Payload for a --bypass job is the encoded/obfuscated variant, so
+		// AnalyzeResponse finding it verbatim in the response only proves the
+		// server echoed back what it was sent, not that a browser will ever
+		// decode and execute it. Absent a browser sink hit, a RawReflection
+		// is kept only if bypassConfirmed decodes the chain exactly back to
+		// the original payload; that demotes variants whose chain doesn't
+		// round-trip, while an encoded payload echoed back unchanged still
+		// counts as RawReflection.
+		if analysis.Type == scanner.RawReflection && job.Mutation != "" && !browserVerified && !bypassConfirmed(job) {
+			analysis.Type = scanner.EscapedReflection
+		}
+
+		// On a raw reflection, record where the payload landed in
+		// ScanResult.Context for the reports; no further payloads are sent here
+		var reflectionContext scanner.ReflectionContext
+		if analysis.Type == scanner.RawReflection {
+			reflectionContext = scanner.DetectContext(result.ResponseBody, job.Payload)
+		}
+
 		scanResult := scanner.ScanResult{
-			Parameter:       param,
-			Payload:         payload,
+			Parameter:       job.Parameter,
+			Payload:         job.Payload,
 			ReflectionType:  analysis.Type,
 			BrowserVerified: browserVerified,
 			XSSEventType:    xssEventType,
+			Context:         reflectionContext,
+			SinkHits:        sinkHits,
+			Mutations:       jobMutations(job.Mutation),
+		}
+		printJobResult(config, job, scanResult)
+
+		return []scanner.ScanResult{scanResult}, throttled
+	}
+}
+
+// printJobResult prints the one-line status for a completed job. Jobs
+// complete out of order across workers, so unlike the old serial loop this
+// logs a single line per payload rather than a multi-line block.
+func printJobResult(config *Config, job scanner.Job, result scanner.ScanResult) {
+	switch result.ReflectionType {
+	case scanner.Blocked:
+		if config.ShowAll {
+			fmt.Printf("[x] Blocked by WAF (param: %s): %s\n", job.Parameter, job.Payload)
 		}
-		results = append(results, scanResult)
+	case scanner.RawReflection:
+		if result.BrowserVerified {
+			fmt.Printf("[+++] VERIFIED XSS (param: %s, event: %s()): %s\n", job.Parameter, result.XSSEventType, job.Payload)
+		} else if config.BrowserVerify {
+			fmt.Printf("[+] RAW XSS FOUND, not verified in browser (param: %s): %s\n", job.Parameter, job.Payload)
+		} else {
+			fmt.Printf("[+] RAW XSS FOUND (param: %s): %s\n", job.Parameter, job.Payload)
+		}
+	case scanner.EscapedReflection:
+		if config.ShowAll {
+			fmt.Printf("[~] Escaped reflection (param: %s): %s\n", job.Parameter, job.Payload)
+		}
+	case scanner.NoReflection:
+		if config.ShowAll {
+			fmt.Printf("[-] No reflection (param: %s): %s\n", job.Parameter, job.Payload)
+		}
+	}
+}
+
+// printParamSummaries prints the same per-parameter raw/escaped/verified
+// counts the old serial loop printed inline, computed from the full result
+// set now that jobs for a parameter complete out of order
+func printParamSummaries(config *Config, results []scanner.ScanResult) {
+	type counts struct{ raw, escaped, verified int }
+	byParam := make(map[string]*counts, len(config.Params))
+	for _, param := range config.Params {
+		byParam[param] = &counts{}
+	}
 
-		// Print results based on reflection type
-		switch analysis.Type {
+	for _, r := range results {
+		c, ok := byParam[r.Parameter]
+		if !ok {
+			continue
+		}
+		switch r.ReflectionType {
 		case scanner.RawReflection:
-			rawHitCount++
-
-			// Print different message based on browser verification
-			if config.BrowserVerify && browserVerified {
-				fmt.Printf("\n[+++] VERIFIED XSS (Executed in Browser!)\n")
-				fmt.Printf("    Param: %s\n", param)
-				fmt.Printf("    Payload: %s\n", payload)
-				fmt.Printf("    Event Type: %s()\n", xssEventType)
-				fmt.Println()
-			} else if config.BrowserVerify && !browserVerified {
-				fmt.Printf("\n[+] RAW XSS FOUND (Static Analysis - Not Verified in Browser)\n")
-				fmt.Printf("    Param: %s\n", param)
-				fmt.Printf("    Payload: %s\n", payload)
-				fmt.Println()
-			} else {
-				fmt.Printf("\n[+] RAW XSS FOUND\n")
-				fmt.Printf("    Param: %s\n", param)
-				fmt.Printf("    Payload: %s\n", payload)
-				fmt.Println()
+			c.raw++
+			if r.BrowserVerified {
+				c.verified++
 			}
+		case scanner.EscapedReflection:
+			c.escaped++
+		}
+	}
+
+	fmt.Println()
+	for _, param := range config.Params {
+		c := byParam[param]
+		if c.raw == 0 && c.escaped == 0 {
+			fmt.Printf("[-] No reflections found for param: %s\n", param)
+		} else if config.BrowserVerify && c.verified > 0 {
+			fmt.Printf("[*] Summary for param '%s': %d raw (%d verified in browser), %d escaped\n", param, c.raw, c.verified, c.escaped)
+		} else {
+			fmt.Printf("[*] Summary for param '%s': %d raw, %d escaped\n", param, c.raw, c.escaped)
+		}
+	}
+}
 
-			// Stop testing this parameter if --stop-on-hit is enabled
-			if config.StopOnHit {
-				fmt.Printf("[*] Stopping tests for param '%s' (--stop-on-hit enabled)\n\n", param)
-				return results
+// showProgress prints a single, repeatedly-overwritten status line with
+// jobs completed, live req/s, verified count and an ETA until done is closed
+func showProgress(engine *scanner.ScanEngine, done <-chan struct{}) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	start := time.Now()
+	last := engine.Stats()
+	lastTick := start
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			stats := engine.Stats()
+			elapsed := now.Sub(lastTick).Seconds()
+			rps := 0.0
+			if elapsed > 0 {
+				rps = float64(stats.Requests-last.Requests) / elapsed
 			}
 
-		case scanner.EscapedReflection:
-			escapedHitCount++
-			// Only show escaped reflections if --show flag is enabled
-			if config.ShowAll {
-				fmt.Printf("\n[~] Escaped reflection\n")
-				fmt.Printf("    Param: %s\n", param)
-				fmt.Printf("    Payload: %s\n", payload)
-				fmt.Println()
+			eta := "?"
+			if avgRPS := float64(stats.Completed) / now.Sub(start).Seconds(); avgRPS > 0 {
+				remaining := stats.Total - stats.Completed
+				eta = time.Duration(float64(remaining)/avgRPS*float64(time.Second)).Round(time.Second).String()
 			}
 
-		case scanner.NoReflection:
-			// Show all payloads if --show flag is enabled
-			if config.ShowAll {
-				fmt.Printf("[-] No reflection\n")
-				fmt.Printf("    Param: %s\n", param)
-				fmt.Printf("    Payload: %s\n", payload)
-				fmt.Println()
+			fmt.Printf("\r[*] %d/%d jobs | %.1f req/s | %d verified | %d blocked | ETA %s   ",
+				stats.Completed, stats.Total, rps, stats.Verified, stats.Blocked, eta)
+			last = stats
+			lastTick = now
+		}
+	}
+}
+
+// runStoredScan tests for stored XSS either against an explicit
+// --submit-url/--verify-url pair, or by crawling the target (if --crawl is
+// set) or treating --url as the only page, then for each discovered
+// parameter injecting a uniquely-tagged marker payload and checking every
+// other page for out-of-band execution
+func runStoredScan(config *Config) []scanner.ScanResult {
+	bv, err := newStartedBrowserVerifier(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Stored XSS mode requires a browser: %v\n", err)
+		return nil
+	}
+	defer bv.Close()
+
+	if config.SubmitURL != "" {
+		var results []scanner.ScanResult
+		for _, param := range config.Params {
+			result, err := scanner.RunStoredXSSWorkflowURLs(bv, config.SubmitURL, config.VerifyURL, param)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Stored XSS check failed for param %s: %v\n", param, err)
+				continue
+			}
+			if result != nil {
+				fmt.Printf("\n[+++] STORED XSS FOUND\n    Source: %s\n    Sink: %s\n\n", result.SourceURL, result.SinkURL)
+				results = append(results, *result)
 			}
 		}
+		return results
 	}
 
-	// Print summary for this parameter
-	if rawHitCount == 0 && escapedHitCount == 0 {
-		fmt.Printf("[-] No reflections found for param: %s\n\n", param)
-	} else {
-		if config.BrowserVerify && verifiedHitCount > 0 {
-			fmt.Printf("[*] Summary for param '%s': %d raw (%d verified in browser), %d escaped\n\n",
-				param, rawHitCount, verifiedHitCount, escapedHitCount)
+	pages := []scanner.CrawledPage{{URL: config.URL, Params: config.Params}}
+	if config.Crawl {
+		fmt.Printf("[*] Crawling from %s (max depth %d)...\n", config.URL, config.MaxCrawlDepth)
+		crawler := scanner.NewCrawler(config.MaxCrawlDepth, config.CrawlCrossOrigin, config.CrawlRespectRobots)
+		crawled, err := crawler.Crawl(config.URL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] Crawl failed: %v\n", err)
 		} else {
-			fmt.Printf("[*] Summary for param '%s': %d raw, %d escaped\n\n", param, rawHitCount, escapedHitCount)
+			pages = crawled
+			fmt.Printf("[*] Discovered %d pages\n\n", len(pages))
+		}
+	}
+
+	var results []scanner.ScanResult
+	for _, page := range pages {
+		for _, param := range storedParamsFor(page) {
+			result, err := scanner.RunStoredXSSWorkflow(bv, page, param, pages)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[!] Stored XSS check failed for %s param %s: %v\n", page.URL, param, err)
+				continue
+			}
+			if result != nil {
+				fmt.Printf("\n[+++] STORED XSS FOUND\n    Source: %s\n    Sink: %s\n\n", result.SourceURL, result.SinkURL)
+				results = append(results, *result)
+			}
 		}
 	}
 
 	return results
 }
 
+// storedParamsFor unions a crawled page's query parameters with every input
+// name declared by its forms, deduped, so a form-only input (never seen in
+// a URL's query string) still gets a stored-XSS workflow run against it.
+func storedParamsFor(page scanner.CrawledPage) []string {
+	seen := make(map[string]bool, len(page.Params))
+	params := make([]string, 0, len(page.Params))
+	for _, param := range page.Params {
+		if !seen[param] {
+			seen[param] = true
+			params = append(params, param)
+		}
+	}
+	for _, form := range page.Forms {
+		for _, input := range form.Inputs {
+			if !seen[input] {
+				seen[input] = true
+				params = append(params, input)
+			}
+		}
+	}
+	return params
+}
+
+// runDOMScan tries each payload in the URL fragment (location.hash), and
+// also in each parameter's query string, reporting any that trip a
+// client-side sink
+func runDOMScan(config *Config, payloads []string) []scanner.ScanResult {
+	bv, err := newStartedBrowserVerifier(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] DOM XSS mode requires a browser: %v\n", err)
+		return nil
+	}
+	defer bv.Close()
+
+	var results []scanner.ScanResult
+	for _, payload := range payloads {
+		result, err := scanner.RunDOMXSSWorkflow(bv, config.URL, payload)
+		if err != nil {
+			continue
+		}
+		if result != nil {
+			fmt.Printf("\n[+++] DOM XSS FOUND (fragment)\n    Payload: %s\n    Sink: %s\n\n", payload, result.XSSEventType)
+			results = append(results, *result)
+		}
+	}
+
+	for _, param := range config.Params {
+		for _, payload := range payloads {
+			result, err := scanner.RunDOMXSSWorkflowQuery(bv, config.URL, param, payload)
+			if err != nil {
+				continue
+			}
+			if result != nil {
+				fmt.Printf("\n[+++] DOM XSS FOUND (query param: %s)\n    Payload: %s\n    Sink: %s\n\n", param, payload, result.XSSEventType)
+				results = append(results, *result)
+			}
+		}
+	}
+
+	return results
+}
+
+// newStartedBrowserVerifier initializes and starts a BrowserVerifier using
+// the config's browser settings
+func newStartedBrowserVerifier(config *Config) (*scanner.BrowserVerifier, error) {
+	bv, err := scanner.NewBrowserVerifier(scanner.BrowserConfig{
+		ChromeDriverPath: config.ChromeDriverPath,
+		Headless:         true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := bv.Start(); err != nil {
+		return nil, err
+	}
+	return bv, nil
+}
+
+// deliverReport hands a saved report file to the configured --report-sink. A
+// delivery failure is logged and otherwise ignored, the same as the
+// report-generation step above it: the local file was already saved
+// successfully, so a sink hiccup shouldn't be treated as fatal.
+func deliverReport(sink report.Sink, localPath string) {
+	if sink == nil || sink.Name() == "local" {
+		return
+	}
+
+	fmt.Printf("[*] Delivering %s to %s...\n", localPath, sink.Name())
+	if err := sink.Send(localPath); err != nil {
+		fmt.Fprintf(os.Stderr, "[!] Failed to deliver %s to %s: %v\n", localPath, sink.Name(), err)
+		return
+	}
+	fmt.Printf("[+] Delivered to %s\n", sink.Name())
+}
+
 // printBanner prints the application banner
 func printBanner() {
 	banner := `
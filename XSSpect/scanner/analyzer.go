@@ -11,6 +11,10 @@ const (
 	NoReflection ReflectionType = iota
 	EscapedReflection
 	RawReflection
+	// Blocked indicates the request was intercepted by a WAF before it ever
+	// reached the application, as opposed to NoReflection where the
+	// application itself simply didn't reflect the payload
+	Blocked
 )
 
 // String returns the string representation of ReflectionType
@@ -22,6 +26,8 @@ func (r ReflectionType) String() string {
 		return "ESCAPED"
 	case NoReflection:
 		return "NO_REFLECTION"
+	case Blocked:
+		return "BLOCKED"
 	default:
 		return "UNKNOWN"
 	}
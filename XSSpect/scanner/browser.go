@@ -1,34 +1,43 @@
 package scanner
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
-	"github.com/tebeka/selenium"
-	"github.com/tebeka/selenium/chrome"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
 )
 
 // BrowserConfig holds configuration for browser-based verification
 type BrowserConfig struct {
-	ChromeDriverPath string
+	ChromeDriverPath string // retained for CLI/back-compat; chromedp drives Chrome directly and doesn't need a driver binary
 	Headless         bool
 	Timeout          time.Duration
 }
 
-// BrowserVerifier handles browser-based XSS verification
+// SinkHit records a single dangerous-sink invocation observed while a page
+// with an injected payload was loaded
+type SinkHit struct {
+	Sink     string // e.g. "alert", "eval", "innerHTML", "document.write"
+	Argument string // the value passed to the sink, truncated by the hook if huge
+	Stack    string // JS call stack captured at the moment the sink fired
+}
+
+// BrowserVerifier drives a real Chrome instance over the DevTools Protocol
+// and instruments it to catch XSS execution across a range of sinks, not
+// just alert/confirm/prompt dialogs
 type BrowserVerifier struct {
-	service *selenium.Service
-	driver  selenium.WebDriver
-	config  BrowserConfig
+	allocCtx    context.Context
+	allocStop   context.CancelFunc
+	browserCtx  context.Context
+	browserStop context.CancelFunc
+	config      BrowserConfig
 }
 
 // NewBrowserVerifier creates a new browser verifier
 func NewBrowserVerifier(config BrowserConfig) (*BrowserVerifier, error) {
-	// Set defaults
-	if config.ChromeDriverPath == "" {
-		config.ChromeDriverPath = "chromedriver"
-	}
 	if config.Timeout == 0 {
 		config.Timeout = 10 * time.Second
 	}
@@ -38,190 +47,182 @@ func NewBrowserVerifier(config BrowserConfig) (*BrowserVerifier, error) {
 	}, nil
 }
 
-// Start initializes the browser
+// Start launches the Chrome instance used for verification
 func (bv *BrowserVerifier) Start() error {
-	// Start Selenium service with retry and better error handling
-	opts := []selenium.ServiceOption{
-		selenium.Output(nil), // Suppress ChromeDriver logs
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("disable-web-security", true),
+		chromedp.Flag("disable-popup-blocking", true),
+	)
+	if !bv.config.Headless {
+		opts = append(opts, chromedp.Flag("headless", false))
 	}
 
-	// Use port 0 to let the OS choose an available port
-	const port = 9515 // ChromeDriver default port
+	allocCtx, allocStop := chromedp.NewExecAllocator(context.Background(), opts...)
+	browserCtx, browserStop := chromedp.NewContext(allocCtx)
 
-	service, err := selenium.NewChromeDriverService(bv.config.ChromeDriverPath, port, opts...)
-	if err != nil {
-		return fmt.Errorf("failed to start ChromeDriver service: %w\n\nPlease ensure:\n1. Chromium/Chrome browser is installed: sudo apt install chromium-browser\n2. ChromeDriver is installed: sudo apt install chromium-chromedriver\n3. ChromeDriver path is correct: %s", err, bv.config.ChromeDriverPath)
-	}
-	bv.service = service
-
-	// Configure Chrome capabilities
-	caps := selenium.Capabilities{"browserName": "chrome"}
-	chromeCaps := chrome.Capabilities{
-		Args: []string{
-			"--no-sandbox",
-			"--disable-dev-shm-usage",
-			"--disable-gpu",
-			"--disable-extensions",
-			"--disable-popup-blocking",
-			"--disable-setuid-sandbox",
-			"--disable-web-security",
-		},
-	}
-
-	if bv.config.Headless {
-		chromeCaps.Args = append(chromeCaps.Args, "--headless=new")
+	// Force the browser to actually start so failures surface here rather
+	// than on the first verification call
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserStop()
+		allocStop()
+		return fmt.Errorf("failed to start Chrome: %w\n\nPlease ensure Chromium/Chrome is installed", err)
 	}
 
-	caps.AddChrome(chromeCaps)
-
-	// Create WebDriver with timeout
-	driver, err := selenium.NewRemote(caps, fmt.Sprintf("http://localhost:%d/wd/hub", port))
-	if err != nil {
-		bv.service.Stop()
-		return fmt.Errorf("failed to create WebDriver: %w\n\nPlease ensure Chromium/Chrome browser is installed", err)
-	}
-	bv.driver = driver
+	bv.allocCtx = allocCtx
+	bv.allocStop = allocStop
+	bv.browserCtx = browserCtx
+	bv.browserStop = browserStop
 
 	return nil
 }
 
-// Close stops the browser and service
+// Close shuts down the Chrome instance
 func (bv *BrowserVerifier) Close() error {
-	if bv.driver != nil {
-		bv.driver.Quit()
+	if bv.browserStop != nil {
+		bv.browserStop()
 	}
-	if bv.service != nil {
-		return bv.service.Stop()
+	if bv.allocStop != nil {
+		bv.allocStop()
 	}
 	return nil
 }
 
-// VerifyXSSExecution verifies if XSS payload actually executes in browser
-// Returns true if alert/confirm/prompt was triggered
-func (bv *BrowserVerifier) VerifyXSSExecution(url string) (bool, string, error) {
-	if bv.driver == nil {
-		return false, "", fmt.Errorf("browser not started")
-	}
-
-	// Set page load timeout
-	err := bv.driver.SetPageLoadTimeout(bv.config.Timeout)
-	if err != nil {
-		return false, "", fmt.Errorf("failed to set timeout: %w", err)
-	}
-
-	// Try to navigate to URL
-	// If an alert/confirm/prompt appears, navigation will fail with "unexpected alert open"
-	err = bv.driver.Get(url)
-
-	// Check if error is due to alert dialog (this means XSS executed!)
+// sinkHookScript is installed via Page.addScriptToEvaluateOnNewDocument so it
+// runs before any page script, which catches payloads that fire immediately
+// on load (something the old "wait then check window.alert" approach missed)
+const sinkHookScript = `
+(function() {
+	window.__xsspect_hits = [];
+
+	function record(sink, argument) {
+		var stack = '';
+		try { throw new Error(); } catch (e) { stack = e.stack || ''; }
+		window.__xsspect_hits.push({
+			Sink: sink,
+			Argument: String(argument).slice(0, 500),
+			Stack: stack
+		});
+	}
+
+	['alert', 'confirm', 'prompt'].forEach(function(name) {
+		window[name] = function(arg) { record(name, arg); return name === 'confirm' ? true : (name === 'prompt' ? null : undefined); };
+	});
+
+	var origEval = window.eval;
+	window.eval = function(src) { record('eval', src); return origEval(src); };
+
+	var OrigFunction = window.Function;
+	window.Function = function() {
+		record('Function', Array.prototype.join.call(arguments, ', '));
+		return OrigFunction.apply(this, arguments);
+	};
+
+	document.write = function(html) { record('document.write', html); };
+	document.writeln = function(html) { record('document.writeln', html); };
+
+	var innerHTMLDescriptor = Object.getOwnPropertyDescriptor(Element.prototype, 'innerHTML');
+	Object.defineProperty(Element.prototype, 'innerHTML', {
+		set: function(value) {
+			record('innerHTML', value);
+			return innerHTMLDescriptor.set.call(this, value);
+		},
+		get: innerHTMLDescriptor.get,
+		configurable: true
+	});
+
+	var origSetTimeout = window.setTimeout;
+	window.setTimeout = function(handler) {
+		if (typeof handler === 'string') { record('setTimeout', handler); }
+		return origSetTimeout.apply(window, arguments);
+	};
+
+	var origSetInterval = window.setInterval;
+	window.setInterval = function(handler) {
+		if (typeof handler === 'string') { record('setInterval', handler); }
+		return origSetInterval.apply(window, arguments);
+	};
+
+	if (window.location && window.location.assign) {
+		var origAssign = window.location.assign.bind(window.location);
+		window.location.assign = function(url) { record('location.assign', url); return origAssign(url); };
+	}
+	if (window.location && window.location.replace) {
+		var origReplace = window.location.replace.bind(window.location);
+		window.location.replace = function(url) { record('location.replace', url); return origReplace(url); };
+	}
+
+	window.addEventListener('message', function(event) {
+		record('postMessage', JSON.stringify(event.data));
+	});
+})();
+`
+
+// VerifyXSSExecution navigates to url with the sink hooks installed before
+// any page script runs, and returns every dangerous sink invocation observed
+func (bv *BrowserVerifier) VerifyXSSExecution(url string) ([]SinkHit, error) {
+	if bv.browserCtx == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	ctx, cancel := context.WithTimeout(bv.browserCtx, bv.config.Timeout)
+	defer cancel()
+
+	var raw string
+	err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			_, err := page.AddScriptToEvaluateOnNewDocument(sinkHookScript).Do(ctx)
+			return err
+		}),
+		chromedp.Navigate(url),
+		chromedp.Sleep(500*time.Millisecond),
+		chromedp.Evaluate(`JSON.stringify(window.__xsspect_hits || [])`, &raw),
+	)
 	if err != nil {
-		errMsg := err.Error()
-
-		// Detect if alert/confirm/prompt was triggered
-		if strings.Contains(errMsg, "unexpected alert open") {
-			// XSS detected! An alert dialog appeared
-			xssType := "alert" // Default to alert
-
-			if strings.Contains(errMsg, "Alert text") {
-				xssType = "alert"
-			} else if strings.Contains(errMsg, "confirmation") {
-				xssType = "confirm"
-			} else if strings.Contains(errMsg, "prompt") {
-				xssType = "prompt"
-			}
-
-			// Try to dismiss the alert so browser can continue
-			bv.driver.DismissAlert()
-
-			return true, xssType, nil
-		}
-
-		// Other errors (timeout, network issues, etc.)
-		if !strings.Contains(errMsg, "timeout") {
-			return false, "", fmt.Errorf("failed to load page: %w", err)
-		}
+		return nil, fmt.Errorf("failed to load page: %w", err)
 	}
 
-	// If page loaded without error, inject detection script and check
-	detectionScript := `
-		window.__xss_detected = false;
-		window.__xss_type = '';
-		
-		// Override functions to detect calls
-		window.alert = function(msg) {
-			window.__xss_detected = true;
-			window.__xss_type = 'alert';
-			return true;
-		};
-		
-		window.confirm = function(msg) {
-			window.__xss_detected = true;
-			window.__xss_type = 'confirm';
-			return true;
-		};
-		
-		window.prompt = function(msg, defaultText) {
-			window.__xss_detected = true;
-			window.__xss_type = 'prompt';
-			return null;
-		};
-	`
-
-	// Execute detection script
-	_, err = bv.driver.ExecuteScript(detectionScript, nil)
-	if err != nil {
-		// If script injection fails, try to check for alert anyway
-		_, alertErr := bv.driver.AlertText()
-		if alertErr == nil {
-			// Alert is present!
-			bv.driver.DismissAlert()
-			return true, "alert", nil
+	var hits []SinkHit
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &hits); err != nil {
+			return nil, fmt.Errorf("failed to parse sink hits: %w", err)
 		}
 	}
 
-	// Wait a bit for any scripts to execute
-	time.Sleep(500 * time.Millisecond)
+	return hits, nil
+}
 
-	// Check if there's an alert present
-	_, alertErr := bv.driver.AlertText()
-	if alertErr == nil {
-		// Alert dialog is present - XSS detected!
-		bv.driver.DismissAlert()
-		return true, "alert", nil
+// VerifyStoredMarker navigates to url and reports whether window.__xsspect
+// equals token, i.e. whether a payload stored on a different page rendered
+// and executed here
+func (bv *BrowserVerifier) VerifyStoredMarker(url, token string) (bool, error) {
+	if bv.browserCtx == nil {
+		return false, fmt.Errorf("browser not started")
 	}
 
-	// Check if XSS was detected via our injected script
-	detected, err := bv.driver.ExecuteScript("return window.__xss_detected || false;", nil)
-	if err != nil {
-		return false, "", fmt.Errorf("failed to check detection: %w", err)
-	}
+	ctx, cancel := context.WithTimeout(bv.browserCtx, bv.config.Timeout)
+	defer cancel()
 
-	xssType, err := bv.driver.ExecuteScript("return window.__xss_type || '';", nil)
+	var marker string
+	err := chromedp.Run(ctx,
+		chromedp.Navigate(url),
+		chromedp.Sleep(500*time.Millisecond),
+		chromedp.Evaluate(`window.__xsspect || ''`, &marker),
+	)
 	if err != nil {
-		xssType = ""
-	}
-
-	isDetected := false
-	if detectedBool, ok := detected.(bool); ok {
-		isDetected = detectedBool
-	}
-
-	xssTypeStr := ""
-	if xssTypeString, ok := xssType.(string); ok {
-		xssTypeStr = xssTypeString
+		return false, fmt.Errorf("failed to load page: %w", err)
 	}
 
-	return isDetected, xssTypeStr, nil
+	return marker == token, nil
 }
 
 // VerifyWithRetry verifies XSS with retry logic
-func (bv *BrowserVerifier) VerifyWithRetry(url string, maxRetries int) (bool, string, error) {
+func (bv *BrowserVerifier) VerifyWithRetry(url string, maxRetries int) ([]SinkHit, error) {
 	var lastErr error
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		detected, xssType, err := bv.VerifyXSSExecution(url)
+		hits, err := bv.VerifyXSSExecution(url)
 		if err == nil {
-			return detected, xssType, nil
+			return hits, nil
 		}
 		lastErr = err
 
@@ -230,5 +231,5 @@ func (bv *BrowserVerifier) VerifyWithRetry(url string, maxRetries int) (bool, st
 		}
 	}
 
-	return false, "", lastErr
+	return nil, lastErr
 }
@@ -0,0 +1,44 @@
+package scanner
+
+import "sync/atomic"
+
+// BrowserPool manages a small fixed set of BrowserVerifier instances so
+// concurrent scan workers can verify in parallel instead of serializing
+// every verification through a single browser
+type BrowserPool struct {
+	verifiers []*BrowserVerifier
+	next      uint64
+}
+
+// NewBrowserPool starts `size` BrowserVerifier instances
+func NewBrowserPool(size int, config BrowserConfig) (*BrowserPool, error) {
+	pool := &BrowserPool{}
+
+	for i := 0; i < size; i++ {
+		bv, err := NewBrowserVerifier(config)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		if err := bv.Start(); err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.verifiers = append(pool.verifiers, bv)
+	}
+
+	return pool, nil
+}
+
+// Get returns one of the pooled verifiers, round-robin
+func (p *BrowserPool) Get() *BrowserVerifier {
+	i := atomic.AddUint64(&p.next, 1)
+	return p.verifiers[int(i)%len(p.verifiers)]
+}
+
+// Close stops every verifier in the pool
+func (p *BrowserPool) Close() {
+	for _, bv := range p.verifiers {
+		bv.Close()
+	}
+}
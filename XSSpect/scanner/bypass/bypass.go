@@ -0,0 +1,336 @@
+// Package bypass generates encoded/obfuscated variants of a base XSS
+// payload aimed at slipping past a WAF or input filter that blocks the
+// literal string. Each transform is a small composable Mutator so callers
+// (--bypass on the CLI) can pick exactly which ones to try, and the applied
+// chain is recorded alongside the result it produced so reports can show
+// defenders precisely which encoding got through.
+package bypass
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Mutator transforms a payload into an encoded/obfuscated variant
+type Mutator func(payload string) string
+
+// Name identifies a registered Mutator, both for --bypass selection and for
+// recording which one produced a given variant in ScanResult.Mutations
+type Name string
+
+const (
+	EntityDec    Name = "entity-dec"
+	EntityHex    Name = "entity-hex"
+	EntityNamed  Name = "entity-named"
+	URL1x        Name = "url1x"
+	URL2x        Name = "url2x"
+	Unicode      Name = "unicode"
+	FromCharCode Name = "fromcharcode"
+	Case         Name = "case"
+	Whitespace   Name = "whitespace"
+	DataURI      Name = "data-uri"
+)
+
+// registry maps every supported --bypass name to its Mutator
+var registry = map[Name]Mutator{
+	EntityDec:    entityDecimal,
+	EntityHex:    entityHex,
+	EntityNamed:  entityNamed,
+	URL1x:        urlSingleEncode,
+	URL2x:        urlDoubleEncode,
+	Unicode:      unicodeEscape,
+	FromCharCode: fromCharCode,
+	Case:         mixedCase,
+	Whitespace:   whitespaceInsert,
+	DataURI:      dataURIWrap,
+}
+
+// All returns every registered mutator name, in a stable order, for
+// --bypass=all
+func All() []Name {
+	return []Name{EntityDec, EntityHex, EntityNamed, URL1x, URL2x, Unicode, FromCharCode, Case, Whitespace, DataURI}
+}
+
+// Parse resolves a comma-separated --bypass value (or "all") into the
+// ordered list of mutator names it names. The order is significant: Chain
+// applies them in the order Parse returns them.
+func Parse(spec string) ([]Name, error) {
+	if spec == "all" {
+		return All(), nil
+	}
+
+	var names []Name
+	for _, raw := range strings.Split(spec, ",") {
+		name := Name(strings.TrimSpace(raw))
+		if name == "" {
+			continue
+		}
+		if _, ok := registry[name]; !ok {
+			return nil, fmt.Errorf("unknown --bypass mutator %q (want one of: entity-dec, entity-hex, entity-named, url1x, url2x, unicode, fromcharcode, case, whitespace, data-uri, or all)", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Apply runs payload through the named mutator and returns the result. It's
+// the caller's job to label the result with the Name that produced it, since
+// that's what ends up in ScanResult.Mutations.
+func Apply(payload string, name Name) string {
+	mutator, ok := registry[name]
+	if !ok {
+		return payload
+	}
+	return mutator(payload)
+}
+
+// Chain folds names together into a single payload by applying each
+// mutator in turn to the previous one's output, e.g. Chain(p, [URL2x,
+// EntityHex]) double-URL-encodes p and then HTML-entity-encodes the result —
+// the actual "encoding chain" --bypass=url2x,entity-hex is named for, as
+// opposed to Apply's independent single-mutator variants.
+func Chain(payload string, names []Name) string {
+	for _, name := range names {
+		payload = Apply(payload, name)
+	}
+	return payload
+}
+
+// Decode reverses the named mutator's transform, returning what Apply would
+// have been given along with whether the reverse mapping is exact. A
+// literal match of an encoded payload in a response only proves the server
+// echoed back what it was sent; decoding lets a caller confirm the encoding
+// actually carries the original payload before trusting that reflection.
+func Decode(payload string, name Name) (string, bool) {
+	switch name {
+	case EntityDec, EntityHex, EntityNamed:
+		return html.UnescapeString(payload), true
+	case URL1x:
+		decoded, err := url.QueryUnescape(payload)
+		if err != nil {
+			return payload, false
+		}
+		return decoded, true
+	case URL2x:
+		once, err := url.QueryUnescape(payload)
+		if err != nil {
+			return payload, false
+		}
+		twice, err := url.QueryUnescape(once)
+		if err != nil {
+			return payload, false
+		}
+		return twice, true
+	case Unicode:
+		return decodeUnicodeEscapes(payload), true
+	case FromCharCode:
+		return decodeFromCharCode(payload)
+	case Case:
+		// mixedCase only ever changes letter case, never which characters
+		// are present, so lower-casing both sides is an exact-enough check
+		return strings.ToLower(payload), true
+	case Whitespace:
+		return strings.ReplaceAll(payload, "/**/", " "), true
+	case DataURI:
+		return decodeDataURI(payload)
+	default:
+		return payload, false
+	}
+}
+
+// DecodeChain reverses Chain by undoing each mutator in the opposite of the
+// order Chain applied them, e.g. DecodeChain(Chain(p, [URL2x, EntityHex]),
+// [URL2x, EntityHex]) first undoes EntityHex, then URL2x, recovering p. The
+// returned bool is false if any step's reverse mapping isn't exact.
+func DecodeChain(payload string, names []Name) (string, bool) {
+	exact := true
+	for i := len(names) - 1; i >= 0; i-- {
+		decoded, ok := Decode(payload, names[i])
+		payload = decoded
+		if !ok {
+			exact = false
+		}
+	}
+	return payload, exact
+}
+
+// decodeUnicodeEscapes reverses unicodeEscape's run of back-to-back \uXXXX
+// sequences (no separators between characters)
+func decodeUnicodeEscapes(payload string) string {
+	var sb strings.Builder
+	for i := 0; i < len(payload); {
+		if i+6 <= len(payload) && payload[i] == '\\' && payload[i+1] == 'u' {
+			if code, err := strconv.ParseInt(payload[i+2:i+6], 16, 32); err == nil {
+				sb.WriteRune(rune(code))
+				i += 6
+				continue
+			}
+		}
+		sb.WriteByte(payload[i])
+		i++
+	}
+	return sb.String()
+}
+
+// decodeFromCharCode reverses fromCharCode's String.fromCharCode(...) call
+// back into the characters it encodes
+func decodeFromCharCode(payload string) (string, bool) {
+	start := strings.Index(payload, "(")
+	end := strings.LastIndex(payload, ")")
+	if start == -1 || end == -1 || end <= start {
+		return payload, false
+	}
+
+	var sb strings.Builder
+	for _, part := range strings.Split(payload[start+1:end], ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return payload, false
+		}
+		sb.WriteRune(rune(code))
+	}
+	return sb.String(), true
+}
+
+// decodeDataURI reverses dataURIWrap's "data:text/html,<percent-encoded>" wrapper
+func decodeDataURI(payload string) (string, bool) {
+	const prefix = "data:text/html,"
+	if !strings.HasPrefix(payload, prefix) {
+		return payload, false
+	}
+	decoded, err := url.QueryUnescape(strings.TrimPrefix(payload, prefix))
+	if err != nil {
+		return payload, false
+	}
+	return decoded, true
+}
+
+// entityDecimal HTML-entity-encodes every character as a decimal reference,
+// e.g. "<" becomes "&#60;" — bypasses filters that only pattern-match on the
+// literal characters rather than decoding entities first
+func entityDecimal(payload string) string {
+	var sb strings.Builder
+	for _, r := range payload {
+		fmt.Fprintf(&sb, "&#%d;", r)
+	}
+	return sb.String()
+}
+
+// entityHex is entityDecimal's hex-reference sibling, e.g. "<" becomes "&#x3c;"
+func entityHex(payload string) string {
+	var sb strings.Builder
+	for _, r := range payload {
+		fmt.Fprintf(&sb, "&#x%x;", r)
+	}
+	return sb.String()
+}
+
+// namedEntities maps a rune to its HTML5 named character reference, for the
+// handful of characters an XSS payload actually needs escaped
+var namedEntities = map[rune]string{
+	'<':  "&lt;",
+	'>':  "&gt;",
+	'"':  "&quot;",
+	'\'': "&apos;",
+	'&':  "&amp;",
+}
+
+// entityNamed HTML-entity-encodes using the named reference where one exists
+// (&lt; &gt; &quot; &apos; &amp;) and falls back to a decimal reference for
+// any other character, e.g. "<a>" becomes "&lt;&#97;&gt;"
+func entityNamed(payload string) string {
+	var sb strings.Builder
+	for _, r := range payload {
+		if name, ok := namedEntities[r]; ok {
+			sb.WriteString(name)
+		} else {
+			fmt.Fprintf(&sb, "&#%d;", r)
+		}
+	}
+	return sb.String()
+}
+
+// urlSingleEncode percent-encodes the payload once
+func urlSingleEncode(payload string) string {
+	return url.QueryEscape(payload)
+}
+
+// urlDoubleEncode percent-encodes the payload twice, which slips past
+// filters that decode the request exactly once before inspecting it
+func urlDoubleEncode(payload string) string {
+	return url.QueryEscape(url.QueryEscape(payload))
+}
+
+// unicodeEscape renders every character as a JS \uXXXX escape, for payloads
+// landing inside a JS string literal where the engine decodes the escape
+// before a string-matching filter ever sees the real characters
+func unicodeEscape(payload string) string {
+	var sb strings.Builder
+	for _, r := range payload {
+		fmt.Fprintf(&sb, "\\u%04x", r)
+	}
+	return sb.String()
+}
+
+// fromCharCode wraps the payload as a JS String.fromCharCode(...) call that
+// reconstructs it at runtime, for use inside an eval/Function/script-block sink
+func fromCharCode(payload string) string {
+	codes := make([]string, 0, len(payload))
+	for _, r := range payload {
+		codes = append(codes, fmt.Sprintf("%d", r))
+	}
+	return fmt.Sprintf("String.fromCharCode(%s)", strings.Join(codes, ","))
+}
+
+// mixedCase alternates the case of every letter in the payload, which
+// defeats filters that only blocklist a specific-case tag/attribute name
+// (e.g. "script" but not "ScRiPt")
+func mixedCase(payload string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range payload {
+		if upper {
+			sb.WriteRune(toUpper(r))
+		} else {
+			sb.WriteRune(toLower(r))
+		}
+		if isLetter(r) {
+			upper = !upper
+		}
+	}
+	return sb.String()
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func toUpper(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r - ('a' - 'A')
+	}
+	return r
+}
+
+func toLower(r rune) rune {
+	if r >= 'A' && r <= 'Z' {
+		return r + ('a' - 'A')
+	}
+	return r
+}
+
+// whitespaceInsert replaces spaces with an HTML/JS comment, e.g.
+// "<img src=x onerror=alert(1)>" becomes "<img/**/src=x/**/onerror=alert(1)>",
+// which slips past filters that require whitespace-delimited tokens
+func whitespaceInsert(payload string) string {
+	return strings.ReplaceAll(payload, " ", "/**/")
+}
+
+// dataURIWrap wraps the payload as a data: URI, for use in URL-attribute
+// contexts (href/src) where the filter blocks javascript: but not data:
+func dataURIWrap(payload string) string {
+	return "data:text/html," + url.QueryEscape(payload)
+}
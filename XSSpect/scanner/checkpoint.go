@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// CheckpointState captures enough scan progress to resume a killed run
+// exactly where it left off
+type CheckpointState struct {
+	Target        string       `json:"target"`
+	CompletedJobs []Job        `json:"completed_jobs"`
+	PendingJobs   []Job        `json:"pending_jobs"`
+	ResultsSoFar  []ScanResult `json:"results_so_far"`
+}
+
+// SaveCheckpoint writes the current scan state to path as JSON
+func SaveCheckpoint(state *CheckpointState, path string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadCheckpoint reads a previously saved scan state from path
+func LoadCheckpoint(path string) (*CheckpointState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state CheckpointState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// CompletedSet returns the completed jobs as a lookup set, so a resumed scan
+// can filter them out of the pending job list
+func (s *CheckpointState) CompletedSet() map[Job]bool {
+	set := make(map[Job]bool, len(s.CompletedJobs))
+	for _, job := range s.CompletedJobs {
+		set[job] = true
+	}
+	return set
+}
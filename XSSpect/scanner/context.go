@@ -0,0 +1,217 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ReflectionContext identifies where in the page markup a payload landed.
+// Knowing the context lets the mutator generate a payload shaped to break
+// out of that specific spot instead of throwing the whole wordlist at it.
+type ReflectionContext int
+
+const (
+	ContextUnknown ReflectionContext = iota
+	ContextHTMLBody
+	ContextHTMLAttrDoubleQuoted
+	ContextHTMLAttrSingleQuoted
+	ContextHTMLAttrUnquoted
+	ContextScriptStringLiteral
+	ContextScriptBlock
+	ContextStyleBlock
+	ContextURLAttribute
+	ContextCommentBlock
+)
+
+// String returns the taxonomy name used in reports and the --contexts flag
+func (c ReflectionContext) String() string {
+	switch c {
+	case ContextHTMLBody:
+		return "html-body"
+	case ContextHTMLAttrDoubleQuoted:
+		return "attr-dq"
+	case ContextHTMLAttrSingleQuoted:
+		return "attr-sq"
+	case ContextHTMLAttrUnquoted:
+		return "attr-unquoted"
+	case ContextScriptStringLiteral:
+		return "js-string"
+	case ContextScriptBlock:
+		return "js-block"
+	case ContextStyleBlock:
+		return "style"
+	case ContextURLAttribute:
+		return "url-attr"
+	case ContextCommentBlock:
+		return "comment"
+	default:
+		return "unknown"
+	}
+}
+
+// contextsByName maps the --contexts flag's taxonomy names back to their
+// ReflectionContext, the inverse of ReflectionContext.String()
+var contextsByName = map[string]ReflectionContext{
+	"html-body":     ContextHTMLBody,
+	"attr-dq":       ContextHTMLAttrDoubleQuoted,
+	"attr-sq":       ContextHTMLAttrSingleQuoted,
+	"attr-unquoted": ContextHTMLAttrUnquoted,
+	"js-string":     ContextScriptStringLiteral,
+	"js-block":      ContextScriptBlock,
+	"style":         ContextStyleBlock,
+	"url-attr":      ContextURLAttribute,
+	"comment":       ContextCommentBlock,
+}
+
+// ParseContext looks up a ReflectionContext by its --contexts taxonomy name
+func ParseContext(name string) (ReflectionContext, error) {
+	if ctx, ok := contextsByName[name]; ok {
+		return ctx, nil
+	}
+	return ContextUnknown, fmt.Errorf("unknown context %q (want one of: html-body, attr-dq, attr-sq, attr-unquoted, js-string, js-block, style, url-attr, comment)", name)
+}
+
+// urlAttributes are attribute names whose value is interpreted as a URL and
+// can therefore be broken out of with a javascript: scheme
+var urlAttributes = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"action": true,
+	"formaction": true,
+}
+
+// DetectContext parses responseBody with the html tokenizer and reports the
+// markup context the payload was reflected into. It falls back to
+// ContextUnknown if the payload can't be located in any token.
+func DetectContext(responseBody, payload string) ReflectionContext {
+	tokenizer := html.NewTokenizer(strings.NewReader(responseBody))
+
+	var inScript, inStyle bool
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		raw := string(tokenizer.Raw())
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			tagName := string(name)
+			if tagName == "script" {
+				inScript = tokenType == html.StartTagToken
+			}
+			if tagName == "style" {
+				inStyle = tokenType == html.StartTagToken
+			}
+
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = tokenizer.TagAttr()
+				attrName := string(key)
+				attrValue := string(val)
+
+				if !strings.Contains(attrValue, payload) {
+					continue
+				}
+
+				if urlAttributes[attrName] {
+					return ContextURLAttribute
+				}
+
+				return classifyAttrQuoting(raw, attrName, payload)
+			}
+
+		case html.TextToken, html.CommentToken:
+			if !strings.Contains(raw, payload) {
+				continue
+			}
+			if tokenType == html.CommentToken {
+				return ContextCommentBlock
+			}
+			if inScript {
+				if inScriptStringLiteral(raw, payload) {
+					return ContextScriptStringLiteral
+				}
+				return ContextScriptBlock
+			}
+			if inStyle {
+				return ContextStyleBlock
+			}
+			return ContextHTMLBody
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tagName := string(name)
+			if tagName == "script" {
+				inScript = false
+			}
+			if tagName == "style" {
+				inStyle = false
+			}
+		}
+	}
+
+	return ContextUnknown
+}
+
+// inScriptStringLiteral reports whether payload's first occurrence in raw
+// (the full text content of a <script> block) sits inside an open quoted
+// string, as opposed to being bare JS source text between statements. It
+// walks raw up to that occurrence tracking whether a ' or " string is
+// currently open, toggling on unescaped quote characters, which is enough
+// to tell the two cases apart for payload reflected into script source
+// without needing a full JS parser.
+func inScriptStringLiteral(raw, payload string) bool {
+	idx := strings.Index(raw, payload)
+	if idx == -1 {
+		return false
+	}
+
+	var quote byte
+	for i := 0; i < idx; i++ {
+		c := raw[i]
+		if quote == 0 {
+			if c == '\'' || c == '"' {
+				quote = c
+			}
+			continue
+		}
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == quote {
+			quote = 0
+		}
+	}
+	return quote != 0
+}
+
+// classifyAttrQuoting inspects the raw start-tag text to determine whether
+// the matched attribute value is double-quoted, single-quoted or unquoted.
+// The tokenizer normalizes attribute values, so the quote style has to be
+// read back from the original bytes.
+func classifyAttrQuoting(rawTag, attrName, payload string) ReflectionContext {
+	idx := strings.Index(rawTag, attrName+"=")
+	if idx == -1 {
+		return ContextHTMLAttrUnquoted
+	}
+
+	afterEquals := rawTag[idx+len(attrName)+1:]
+	if strings.HasPrefix(afterEquals, `"`) {
+		return ContextHTMLAttrDoubleQuoted
+	}
+	if strings.HasPrefix(afterEquals, `'`) {
+		return ContextHTMLAttrSingleQuoted
+	}
+	if strings.Contains(afterEquals, payload) {
+		return ContextHTMLAttrUnquoted
+	}
+
+	return ContextHTMLAttrUnquoted
+}
@@ -0,0 +1,290 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Form represents an HTML form discovered while crawling, along with the
+// input names that can be injected
+type Form struct {
+	Action string
+	Method string
+	Inputs []string
+}
+
+// CrawledPage is a single page discovered during a crawl, along with every
+// form and query parameter found on it
+type CrawledPage struct {
+	URL    string
+	Forms  []Form
+	Params []string
+}
+
+// Crawler BFS-crawls a target starting from a seed URL, honoring same-origin
+// and a maximum depth, enumerating every form and parameter it encounters so
+// a stored/DOM XSS workflow has somewhere to inject and somewhere to check
+type Crawler struct {
+	MaxDepth      int
+	SameOrigin    bool
+	RespectRobots bool
+	MaxPages      int
+	visited       map[string]bool
+	disallowed    []string
+}
+
+// NewCrawler creates a Crawler with sane defaults. allowCrossOrigin lets the
+// crawl follow links off the seed's host instead of staying same-origin;
+// respectRobots fetches the seed's robots.txt first and skips any path it
+// disallows for User-agent: *.
+func NewCrawler(maxDepth int, allowCrossOrigin, respectRobots bool) *Crawler {
+	return &Crawler{
+		MaxDepth:      maxDepth,
+		SameOrigin:    !allowCrossOrigin,
+		RespectRobots: respectRobots,
+		MaxPages:      100,
+		visited:       make(map[string]bool),
+	}
+}
+
+// Crawl performs a BFS crawl starting at seedURL and returns every page
+// discovered within MaxDepth, each with its forms and query parameters
+func (c *Crawler) Crawl(seedURL string) ([]CrawledPage, error) {
+	seed, err := url.Parse(seedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	type queueItem struct {
+		u     string
+		depth int
+	}
+
+	if c.RespectRobots {
+		c.disallowed = fetchDisallowedPaths(seed)
+	}
+
+	queue := []queueItem{{u: seedURL, depth: 0}}
+	var pages []CrawledPage
+
+	for len(queue) > 0 && len(pages) < c.MaxPages {
+		item := queue[0]
+		queue = queue[1:]
+
+		if c.visited[item.u] {
+			continue
+		}
+		c.visited[item.u] = true
+
+		if c.RespectRobots && c.robotsDisallow(item.u) {
+			continue
+		}
+
+		body, err := fetchBody(item.u)
+		if err != nil {
+			continue
+		}
+
+		page := parsePage(item.u, body)
+		pages = append(pages, page)
+
+		if item.depth >= c.MaxDepth {
+			continue
+		}
+
+		for _, link := range extractLinks(item.u, body) {
+			linkURL, err := url.Parse(link)
+			if err != nil {
+				continue
+			}
+			if c.SameOrigin && linkURL.Host != seed.Host {
+				continue
+			}
+			if !c.visited[link] {
+				queue = append(queue, queueItem{u: link, depth: item.depth + 1})
+			}
+		}
+	}
+
+	return pages, nil
+}
+
+// robotsDisallow reports whether pageURL's path matches one of the disallowed
+// prefixes fetched from the seed's robots.txt
+func (c *Crawler) robotsDisallow(pageURL string) bool {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range c.disallowed {
+		if strings.HasPrefix(parsed.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchDisallowedPaths fetches robots.txt from seed's origin and returns the
+// Disallow prefixes listed under a "User-agent: *" block, ignoring every
+// other directive (Allow, Crawl-delay, Sitemap) and every other user agent's
+// block. Returns nil if robots.txt can't be fetched at all, which leaves the
+// crawl unrestricted rather than blocking it on a missing file.
+func fetchDisallowedPaths(seed *url.URL) []string {
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", seed.Scheme, seed.Host)
+	body, err := fetchBody(robotsURL)
+	if err != nil {
+		return nil
+	}
+
+	var disallowed []string
+	relevant := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "user-agent":
+			relevant = value == "*"
+		case "disallow":
+			if relevant && value != "" {
+				disallowed = append(disallowed, value)
+			}
+		}
+	}
+	return disallowed
+}
+
+// fetchBody retrieves a page body with the same client conventions as the
+// rest of the scanner (timeout, User-Agent) but without the payload retry
+// logic SendRequest applies, since a crawl failure just means skip the page
+func fetchBody(pageURL string) (string, error) {
+	client := &http.Client{Timeout: RequestTimeout}
+
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "XSSpect/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bodyBytes), nil
+}
+
+// parsePage extracts forms and query parameters from a page's HTML
+func parsePage(pageURL, body string) CrawledPage {
+	page := CrawledPage{URL: pageURL}
+
+	if parsed, err := url.Parse(pageURL); err == nil {
+		for param := range parsed.Query() {
+			page.Params = append(page.Params, param)
+		}
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	var currentForm *Form
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		name, hasAttr := tokenizer.TagName()
+		tagName := string(name)
+
+		attrs := map[string]string{}
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = tokenizer.TagAttr()
+			attrs[string(key)] = string(val)
+		}
+
+		switch tagName {
+		case "form":
+			form := Form{Action: attrs["action"], Method: strings.ToUpper(attrs["method"])}
+			if form.Method == "" {
+				form.Method = "GET"
+			}
+			page.Forms = append(page.Forms, form)
+			currentForm = &page.Forms[len(page.Forms)-1]
+		case "input", "textarea", "select":
+			if currentForm != nil && attrs["name"] != "" {
+				currentForm.Inputs = append(currentForm.Inputs, attrs["name"])
+			}
+		}
+	}
+
+	return page
+}
+
+// extractLinks pulls every <a href> out of a page's HTML, resolved against
+// the page's own URL
+func extractLinks(pageURL, body string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	var links []string
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		if tokenType != html.StartTagToken {
+			continue
+		}
+
+		name, hasAttr := tokenizer.TagName()
+		if string(name) != "a" {
+			continue
+		}
+
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = tokenizer.TagAttr()
+			if string(key) != "href" {
+				continue
+			}
+
+			resolved, err := base.Parse(string(val))
+			if err != nil {
+				continue
+			}
+			resolved.Fragment = ""
+			links = append(links, resolved.String())
+		}
+	}
+
+	return links
+}
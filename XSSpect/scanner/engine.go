@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Job is a single (parameter, payload) unit of scan work. It must stay a
+// comparable struct: CheckpointState.CompletedSet keys a map on it.
+type Job struct {
+	Parameter string
+	Payload   string
+	// Mutation names the --bypass encoding chain already baked into
+	// Payload, if any (comma-joined, e.g. "url2x,entity-hex"), so the
+	// result it produces can be traced back to exactly which bypass
+	// variant was tried. Empty for an ordinary (unmutated) payload.
+	Mutation string
+	// BasePayload is the original, unmutated payload Payload was derived
+	// from via Mutation. Only set alongside Mutation, so a literal
+	// reflection of the encoded Payload can be decoded and checked against
+	// it before trusting that the encoding actually carries the payload.
+	BasePayload string
+}
+
+// EngineStats holds live counters exposed via --progress and --metrics-addr
+type EngineStats struct {
+	Requests  int64
+	Verified  int64
+	Blocked   int64
+	Completed int64
+	Total     int64
+}
+
+// ProcessFunc performs one job and reports whether the target responded with
+// a throttling status (429/503) so the engine can back off. ctx is cancelled
+// when the scan is interrupted (e.g. Ctrl-C), so long-running implementations
+// should pass it on to anything that blocks, such as scanner.SendRequest.
+type ProcessFunc func(ctx context.Context, job Job) (results []ScanResult, throttled bool)
+
+// ScanEngine runs a pool of worker goroutines over a queue of jobs, applying
+// a per-host token-bucket rate limit and backing off when the target starts
+// returning too many 429/503 responses in a row
+type ScanEngine struct {
+	Workers          int
+	RPS              float64
+	BackoffThreshold int
+
+	limiter *rate.Limiter
+	stats   EngineStats
+
+	mu                sync.Mutex
+	consecutiveBlocks int
+	backoffUntil      time.Time
+}
+
+// NewScanEngine creates a ScanEngine with the given concurrency and per-host
+// requests-per-second cap
+func NewScanEngine(workers int, rps float64) *ScanEngine {
+	if workers < 1 {
+		workers = 1
+	}
+	if rps <= 0 {
+		rps = 5
+	}
+	return &ScanEngine{
+		Workers:          workers,
+		RPS:              rps,
+		BackoffThreshold: 3,
+		limiter:          rate.NewLimiter(rate.Limit(rps), 1),
+	}
+}
+
+// Stats returns a snapshot of the engine's live counters
+func (e *ScanEngine) Stats() EngineStats {
+	return EngineStats{
+		Requests:  atomic.LoadInt64(&e.stats.Requests),
+		Verified:  atomic.LoadInt64(&e.stats.Verified),
+		Blocked:   atomic.LoadInt64(&e.stats.Blocked),
+		Completed: atomic.LoadInt64(&e.stats.Completed),
+		Total:     atomic.LoadInt64(&e.stats.Total),
+	}
+}
+
+// Run dispatches jobs across Workers goroutines, honoring the rate limiter
+// and adaptive backoff. onResult is invoked from worker goroutines as each
+// job completes, so callers needing shared state (checkpoints, progress)
+// must synchronize it themselves.
+func (e *ScanEngine) Run(ctx context.Context, jobs []Job, process ProcessFunc, onResult func(Job, []ScanResult)) {
+	atomic.StoreInt64(&e.stats.Total, int64(len(jobs)))
+
+	jobCh := make(chan Job)
+	var wg sync.WaitGroup
+
+	for i := 0; i < e.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				if ctx.Err() != nil {
+					return
+				}
+
+				e.waitForSlot(ctx)
+
+				results, throttled := process(ctx, job)
+				atomic.AddInt64(&e.stats.Requests, 1)
+				atomic.AddInt64(&e.stats.Completed, 1)
+				for _, result := range results {
+					if result.BrowserVerified {
+						atomic.AddInt64(&e.stats.Verified, 1)
+					}
+					if result.ReflectionType == Blocked {
+						atomic.AddInt64(&e.stats.Blocked, 1)
+					}
+				}
+
+				e.recordThrottle(throttled)
+
+				if onResult != nil {
+					onResult(job, results)
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, job := range jobs {
+		select {
+		case jobCh <- job:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobCh)
+
+	wg.Wait()
+}
+
+// waitForSlot blocks for the rate limiter and any active backoff window
+func (e *ScanEngine) waitForSlot(ctx context.Context) {
+	e.mu.Lock()
+	until := e.backoffUntil
+	e.mu.Unlock()
+
+	if wait := time.Until(until); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	e.limiter.Wait(ctx)
+}
+
+// recordThrottle tracks consecutive throttling responses and opens a
+// backoff window once BackoffThreshold is hit in a row
+func (e *ScanEngine) recordThrottle(throttled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !throttled {
+		e.consecutiveBlocks = 0
+		return
+	}
+
+	e.consecutiveBlocks++
+	if e.consecutiveBlocks >= e.BackoffThreshold {
+		backoff := time.Duration(e.consecutiveBlocks) * time.Second
+		e.backoffUntil = time.Now().Add(backoff)
+	}
+}
+
+// IsThrottleStatus reports whether an HTTP status code is the kind of
+// throttling response (429/503) the engine should back off on
+func IsThrottleStatus(statusCode int) bool {
+	return statusCode == 429 || statusCode == 503
+}
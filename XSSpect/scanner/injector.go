@@ -35,6 +35,19 @@ func BuildRequestURL(baseURL, parameter, payload string) (string, error) {
 	return InjectPayload(baseURL, parameter, payload)
 }
 
+// BuildFragmentURL places the payload in the URL fragment (location.hash)
+// instead of a query parameter, for driving DOM-XSS sinks that read
+// location.hash directly
+func BuildFragmentURL(baseURL, payload string) (string, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	parsedURL.Fragment = payload
+	return parsedURL.String(), nil
+}
+
 // ExtractBaseURL extracts the base URL without query parameters
 func ExtractBaseURL(rawURL string) (string, error) {
 	parsedURL, err := url.Parse(rawURL)
@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ServeMetrics starts a minimal Prometheus-text-format endpoint on addr
+// exposing the engine's live counters. It blocks, so callers run it in its
+// own goroutine.
+func ServeMetrics(addr string, engine *ScanEngine) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		stats := engine.Stats()
+		fmt.Fprintf(w, "# TYPE xsspect_requests_total counter\n")
+		fmt.Fprintf(w, "xsspect_requests_total %d\n", stats.Requests)
+		fmt.Fprintf(w, "# TYPE xsspect_verified_total counter\n")
+		fmt.Fprintf(w, "xsspect_verified_total %d\n", stats.Verified)
+		fmt.Fprintf(w, "# TYPE xsspect_blocked_total counter\n")
+		fmt.Fprintf(w, "xsspect_blocked_total %d\n", stats.Blocked)
+		fmt.Fprintf(w, "# TYPE xsspect_completed_total counter\n")
+		fmt.Fprintf(w, "xsspect_completed_total %d\n", stats.Completed)
+		fmt.Fprintf(w, "# TYPE xsspect_jobs_total gauge\n")
+		fmt.Fprintf(w, "xsspect_jobs_total %d\n", stats.Total)
+	})
+	return http.ListenAndServe(addr, mux)
+}
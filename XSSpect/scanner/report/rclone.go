@@ -0,0 +1,33 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// rcloneSink uploads a single report file to an arbitrary rclone remote
+// (anything `rclone` itself knows how to talk to — Google Drive, S3, GCS,
+// SFTP, etc). main.go used to run `rclone sync ./outputs gdrive:csv-data`
+// unconditionally; --report-sink rclone:gdrive:csv-data uploads to that same
+// remote, but copies each report file with `rclone copyto` instead of
+// syncing the whole outputs directory, so remote files are never deleted.
+type rcloneSink struct {
+	remote string
+}
+
+func (s *rcloneSink) Name() string { return "rclone:" + s.remote }
+
+func (s *rcloneSink) Send(localPath string) error {
+	dest := s.remote + "/" + filepath.Base(localPath)
+
+	cmd := exec.Command("rclone", "copyto", localPath, dest)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("rclone copyto %s -> %s: %w (is rclone installed and configured? run: rclone config)", localPath, dest, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3Sink uploads a report file to an S3 bucket/prefix. Credentials and
+// region come from the standard AWS env vars / shared config, same as the
+// AWS CLI, so there's nothing XSSpect-specific to configure beyond the
+// bucket and prefix in --report-sink.
+type s3Sink struct {
+	bucket string
+	prefix string
+}
+
+func (s *s3Sink) Name() string { return "s3://" + s.bucket + "/" + s.prefix }
+
+func (s *s3Sink) Send(localPath string) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer file.Close()
+
+	key := filepath.Base(localPath)
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+
+	client := s3.NewFromConfig(cfg)
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+		Body:   file,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to s3://%s/%s: %w", localPath, s.bucket, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package report delivers finished report artifacts (CSV/JSON/SARIF files
+// already written to disk by the scanner package) to wherever the operator
+// wants them: left alone locally, pushed to an rclone remote, uploaded to S3
+// or GCS, or POSTed to a webhook. main.go used to hardcode an rclone sync to
+// a specific Google Drive remote; that assumption doesn't hold for anyone
+// without that exact remote configured, so sink selection is now explicit.
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sink delivers a single local report file to a destination. Implementations
+// should be safe to reuse across multiple Send calls in the same run (one
+// run can produce a CSV, a JSON and a SARIF file).
+type Sink interface {
+	// Name describes the sink for log output, e.g. "rclone:gdrive:csv-data"
+	Name() string
+	// Send delivers the report file at localPath to the sink's destination
+	Send(localPath string) error
+}
+
+// ParseSink parses a --report-sink value into a Sink. Recognized forms:
+//
+//	(empty)                  local file only, no delivery step
+//	local                    local file only, no delivery step
+//	stdout                   print the report contents to stdout
+//	rclone:<remote>          `rclone copyto <file> <remote>/<basename>`
+//	s3://<bucket>/<prefix>   upload to S3 via aws-sdk-go-v2
+//	gcs://<bucket>/<prefix>  upload to GCS via cloud.google.com/go/storage
+//	webhook:<url>            HTTP POST the file contents to <url>
+func ParseSink(spec string) (Sink, error) {
+	switch {
+	case spec == "" || spec == "local":
+		return localSink{}, nil
+	case spec == "stdout":
+		return stdoutSink{}, nil
+	case strings.HasPrefix(spec, "rclone:"):
+		remote := strings.TrimPrefix(spec, "rclone:")
+		if remote == "" {
+			return nil, fmt.Errorf("rclone sink requires a remote, e.g. rclone:gdrive:csv-data")
+		}
+		return &rcloneSink{remote: remote}, nil
+	case strings.HasPrefix(spec, "s3://"):
+		bucket, prefix := splitBucketPrefix(strings.TrimPrefix(spec, "s3://"))
+		return &s3Sink{bucket: bucket, prefix: prefix}, nil
+	case strings.HasPrefix(spec, "gcs://"):
+		bucket, prefix := splitBucketPrefix(strings.TrimPrefix(spec, "gcs://"))
+		return &gcsSink{bucket: bucket, prefix: prefix}, nil
+	case strings.HasPrefix(spec, "webhook:"):
+		url := strings.TrimPrefix(spec, "webhook:")
+		if url == "" {
+			return nil, fmt.Errorf("webhook sink requires a URL, e.g. webhook:https://example.com/ingest")
+		}
+		return &webhookSink{url: url}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized --report-sink %q (want local, stdout, rclone:<remote>, s3://bucket/prefix, gcs://bucket/prefix, or webhook:<url>)", spec)
+	}
+}
+
+// splitBucketPrefix splits "bucket/some/prefix" into ("bucket", "some/prefix")
+func splitBucketPrefix(s string) (bucket, prefix string) {
+	parts := strings.SplitN(s, "/", 2)
+	bucket = parts[0]
+	if len(parts) == 2 {
+		prefix = parts[1]
+	}
+	return bucket, prefix
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// webhookSink POSTs a report file's raw contents to an HTTP endpoint, with a
+// Content-Type inferred from the file extension so JSON/SARIF consumers get
+// application/json and CSV consumers get text/csv
+type webhookSink struct {
+	url string
+}
+
+func (s *webhookSink) Name() string { return "webhook:" + s.url }
+
+func (s *webhookSink) Send(localPath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s for webhook sink: %w", localPath, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentTypeFor(localPath))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook POST to %s failed: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook POST to %s returned status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// contentTypeFor picks a Content-Type from a report file's extension
+func contentTypeFor(path string) string {
+	switch filepath.Ext(path) {
+	case ".json", ".sarif":
+		return "application/json"
+	case ".csv":
+		return "text/csv"
+	default:
+		return "application/octet-stream"
+	}
+}
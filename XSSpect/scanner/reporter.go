@@ -2,12 +2,36 @@ package scanner
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 )
 
+// VulnClass distinguishes how a vulnerability was triggered: a payload
+// reflected back in the immediate response, one stored server-side and
+// triggered on a later page view, or one executed purely client-side
+type VulnClass int
+
+const (
+	Reflected VulnClass = iota
+	Stored
+	DOM
+)
+
+// String returns the taxonomy name used in reports
+func (v VulnClass) String() string {
+	switch v {
+	case Stored:
+		return "Stored"
+	case DOM:
+		return "DOM"
+	default:
+		return "Reflected"
+	}
+}
+
 // ScanResult holds the result of a single payload test
 type ScanResult struct {
 	Parameter       string
@@ -15,6 +39,12 @@ type ScanResult struct {
 	ReflectionType  ReflectionType
 	BrowserVerified bool
 	XSSEventType    string
+	Context         ReflectionContext
+	SinkHits        []SinkHit
+	VulnClass       VulnClass
+	SourceURL       string   // where the payload was injected
+	SinkURL         string   // where it was observed executing (== SourceURL for reflected)
+	Mutations       []string // --bypass encoding chain applied to Payload, if any
 }
 
 // ScanSummary holds the complete scan summary
@@ -29,7 +59,9 @@ type ScanSummary struct {
 	RawCount             int
 	EscapedCount         int
 	VerifiedCount        int
+	BlockedCount         int
 	BrowserVerifyEnabled bool
+	WAFInfo              *WAFInfo
 }
 
 // SaveReport saves the TXT report to a file
@@ -63,7 +95,12 @@ func SaveCSVReport(summary *ScanSummary, outputPath string) error {
 		"Reflection_Type",
 		"Browser_Verified",
 		"XSS_Event_Type",
+		"Context",
+		"Vuln_Class",
+		"Source_URL",
+		"Sink_URL",
 		"Severity",
+		"Mutations",
 	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
@@ -93,7 +130,12 @@ func SaveCSVReport(summary *ScanSummary, outputPath string) error {
 			reflectionType,
 			browserVerified,
 			result.XSSEventType,
+			result.Context.String(),
+			result.VulnClass.String(),
+			result.SourceURL,
+			result.SinkURL,
 			severity,
+			strings.Join(result.Mutations, ";"),
 		}
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("failed to write CSV row: %w", err)
@@ -103,6 +145,89 @@ func SaveCSVReport(summary *ScanSummary, outputPath string) error {
 	return nil
 }
 
+// jsonReport is the stable schema SaveJSONReport emits. It mirrors
+// ScanSummary/ScanResult but flattens enum types to strings and pulls the
+// first SinkHit's sink/stack up to the top level, so CI tooling can consume
+// it without depending on XSSpect's Go types.
+type jsonReport struct {
+	TargetURL            string       `json:"targetUrl"`
+	Method               string       `json:"method"`
+	Parameters           []string     `json:"parameters"`
+	TotalPayloads        int          `json:"totalPayloads"`
+	StartTime            time.Time    `json:"startTime"`
+	EndTime              time.Time    `json:"endTime"`
+	RawCount             int          `json:"rawCount"`
+	EscapedCount         int          `json:"escapedCount"`
+	VerifiedCount        int          `json:"verifiedCount"`
+	BlockedCount         int          `json:"blockedCount"`
+	BrowserVerifyEnabled bool         `json:"browserVerifyEnabled"`
+	WAFInfo              *WAFInfo     `json:"wafInfo,omitempty"`
+	Results              []jsonResult `json:"results"`
+}
+
+// jsonResult is the per-finding shape within a jsonReport
+type jsonResult struct {
+	Parameter       string   `json:"parameter"`
+	Payload         string   `json:"payload"`
+	ReflectionType  string   `json:"reflectionType"`
+	BrowserVerified bool     `json:"browserVerified"`
+	Context         string   `json:"context"`
+	Sink            string   `json:"sink,omitempty"`
+	Stack           string   `json:"stack,omitempty"`
+	VulnClass       string   `json:"vulnClass"`
+	SourceURL       string   `json:"sourceUrl,omitempty"`
+	SinkURL         string   `json:"sinkUrl,omitempty"`
+	Mutations       []string `json:"mutations,omitempty"`
+}
+
+// SaveJSONReport saves the scan results as JSON in a stable schema intended
+// for CI pipelines (GitHub Advanced Security, GitLab, DefectDojo) to consume
+// directly, without bespoke post-processing
+func SaveJSONReport(summary *ScanSummary, outputPath string) error {
+	report := jsonReport{
+		TargetURL:            summary.TargetURL,
+		Method:               summary.Method,
+		Parameters:           summary.Parameters,
+		TotalPayloads:        summary.TotalPayloads,
+		StartTime:            summary.StartTime,
+		EndTime:              summary.EndTime,
+		RawCount:             summary.RawCount,
+		EscapedCount:         summary.EscapedCount,
+		VerifiedCount:        summary.VerifiedCount,
+		BlockedCount:         summary.BlockedCount,
+		BrowserVerifyEnabled: summary.BrowserVerifyEnabled,
+		WAFInfo:              summary.WAFInfo,
+	}
+
+	for _, result := range summary.Results {
+		jr := jsonResult{
+			Parameter:       result.Parameter,
+			Payload:         result.Payload,
+			ReflectionType:  result.ReflectionType.String(),
+			BrowserVerified: result.BrowserVerified,
+			Context:         result.Context.String(),
+			VulnClass:       result.VulnClass.String(),
+			SourceURL:       result.SourceURL,
+			SinkURL:         result.SinkURL,
+			Mutations:       result.Mutations,
+		}
+		if len(result.SinkHits) > 0 {
+			jr.Sink = result.SinkHits[0].Sink
+			jr.Stack = result.SinkHits[0].Stack
+		}
+		report.Results = append(report.Results, jr)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON report: %w", err)
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save JSON report: %w", err)
+	}
+	return nil
+}
+
 // getSeverity determines the severity level based on the result
 func getSeverity(result ScanResult) string {
 	switch result.ReflectionType {
@@ -113,6 +238,8 @@ func getSeverity(result ScanResult) string {
 		return "High"
 	case EscapedReflection:
 		return "Low"
+	case Blocked:
+		return "Info"
 	default:
 		return "Info"
 	}
@@ -135,13 +262,18 @@ func GenerateBasicReport(summary *ScanSummary) string {
 	sb.WriteString(fmt.Sprintf("Scan Start: %s\n", summary.StartTime.Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("Scan End: %s\n", summary.EndTime.Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("Duration: %s\n", summary.EndTime.Sub(summary.StartTime).Round(time.Second)))
-	sb.WriteString(fmt.Sprintf("Browser Verification: %v\n\n", summary.BrowserVerifyEnabled))
+	sb.WriteString(fmt.Sprintf("Browser Verification: %v\n", summary.BrowserVerifyEnabled))
+	if summary.WAFInfo != nil {
+		sb.WriteString(fmt.Sprintf("WAF Detected: %s (%s)\n", summary.WAFInfo.Name, summary.WAFInfo.Vendor))
+	}
+	sb.WriteString("\n")
 
 	sb.WriteString("RESULTS SUMMARY\n")
 	sb.WriteString("---------------\n")
 	sb.WriteString(fmt.Sprintf("RAW XSS Found: %d\n", summary.RawCount))
 	sb.WriteString(fmt.Sprintf("Browser Verified: %d\n", summary.VerifiedCount))
-	sb.WriteString(fmt.Sprintf("Escaped Reflections: %d\n\n", summary.EscapedCount))
+	sb.WriteString(fmt.Sprintf("Escaped Reflections: %d\n", summary.EscapedCount))
+	sb.WriteString(fmt.Sprintf("Blocked by WAF: %d\n\n", summary.BlockedCount))
 
 	// Risk Assessment
 	sb.WriteString("RISK ASSESSMENT\n")
@@ -168,7 +300,7 @@ func GenerateBasicReport(summary *ScanSummary) string {
 		vulnNum := 1
 		for _, result := range summary.Results {
 			if result.ReflectionType == RawReflection {
-				sb.WriteString(fmt.Sprintf("\n[%d] Parameter: %s\n", vulnNum, result.Parameter))
+				sb.WriteString(fmt.Sprintf("\n[%d] Parameter: %s (%s)\n", vulnNum, result.Parameter, result.VulnClass.String()))
 				sb.WriteString(fmt.Sprintf("    Payload: %s\n", result.Payload))
 				if result.BrowserVerified {
 					sb.WriteString(fmt.Sprintf("    Status: VERIFIED (%s() executed in browser)\n", result.XSSEventType))
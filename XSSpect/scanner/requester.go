@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,12 +20,30 @@ const (
 type RequestConfig struct {
 	URL    string
 	Method string
+	// Headers, if set, are applied to the request in addition to the
+	// default User-Agent. Used by --request-file scans that need to
+	// replay arbitrary headers/cookies; left nil for ordinary scans.
+	Headers http.Header
+	// Body, if non-empty, is sent as the request body. Used by
+	// --request-file scans with a POST/PUT body; left empty for ordinary
+	// query-string scans.
+	Body string
+	// Ctx, if set, cancels the request (and aborts any further retries) when
+	// it's done. Defaults to context.Background() when left nil.
+	Ctx context.Context
+	// Jar, if set, persists cookies the target sets across requests and
+	// attaches them back on later ones, the way a real browser session
+	// would. Used once a WAF is detected (see CookieSession), since many
+	// WAFs expect a consistent session and are likelier to block requests
+	// that don't carry one forward. Left nil for ordinary scans.
+	Jar http.CookieJar
 }
 
 // RequestResult contains the result of an HTTP request
 type RequestResult struct {
 	StatusCode   int
 	ResponseBody string
+	Headers      http.Header
 	Error        error
 }
 
@@ -32,25 +51,48 @@ type RequestResult struct {
 // Retries only on network errors (timeout, connection failures)
 // Does NOT retry on valid HTTP responses (200, 403, 500, etc.)
 func SendRequest(config RequestConfig) RequestResult {
+	ctx := config.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	var lastErr error
 	var result RequestResult
 
 	// Create HTTP client with timeout
 	client := &http.Client{
 		Timeout: RequestTimeout,
+		Jar:     config.Jar,
 	}
 
 	// Try up to MaxRetries + 1 times (initial attempt + retries)
 	for attempt := 0; attempt <= MaxRetries; attempt++ {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err()
+			return result
+		}
+
 		// Create request
-		req, err := http.NewRequest(config.Method, config.URL, nil)
+		var body io.Reader
+		if config.Body != "" {
+			body = strings.NewReader(config.Body)
+		}
+		req, err := http.NewRequestWithContext(ctx, config.Method, config.URL, body)
 		if err != nil {
 			lastErr = err
 			continue
 		}
 
-		// Set a basic User-Agent to avoid being blocked by some servers
+		// Set a basic User-Agent to avoid being blocked by some servers,
+		// then layer on any caller-supplied headers (e.g. from a
+		// --request-file) which take priority
 		req.Header.Set("User-Agent", "XSSpect/1.0")
+		for name, values := range config.Headers {
+			req.Header.Del(name)
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
 
 		// Send request
 		resp, err := client.Do(req)
@@ -78,6 +120,7 @@ func SendRequest(config RequestConfig) RequestResult {
 
 		result.StatusCode = resp.StatusCode
 		result.ResponseBody = string(bodyBytes)
+		result.Headers = resp.Header
 		result.Error = nil
 		return result
 	}
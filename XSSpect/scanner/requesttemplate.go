@@ -0,0 +1,174 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultMarker is the placeholder ParseRequestFile looks for when no
+// --marker override is given, matching the {INJECT} convention sqlmap -r
+// and Burp's Intruder both use
+const DefaultMarker = "{INJECT}"
+
+// RequestTemplate is a raw HTTP request (request line + headers + optional
+// body) read from a --request-file, with one or more marker occurrences
+// that get replaced by each payload in turn. This is what lets a scan cover
+// endpoints that need custom headers, cookies, or a POST body instead of
+// only --url + --params.
+type RequestTemplate struct {
+	Method  string
+	Path    string // request-line target, e.g. "/search?q={INJECT}"
+	Headers http.Header
+	Body    string
+}
+
+// ParseRequestFile reads a raw HTTP request file in the sqlmap -r / Burp
+// "Copy as request" format: a request line, headers, a blank line, then an
+// optional body
+func ParseRequestFile(path string) (*RequestTemplate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open request file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("request file %s is empty", path)
+	}
+	requestLine := strings.TrimRight(scanner.Text(), "\r")
+	parts := strings.Fields(requestLine)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid request line %q in %s (want \"METHOD /path HTTP/1.1\")", requestLine, path)
+	}
+
+	tmpl := &RequestTemplate{
+		Method:  strings.ToUpper(parts[0]),
+		Path:    parts[1],
+		Headers: make(http.Header),
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break // blank line separates headers from body
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header line %q in %s", line, path)
+		}
+		tmpl.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	var bodyLines []string
+	for scanner.Scan() {
+		bodyLines = append(bodyLines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading request file %s: %w", path, err)
+	}
+	tmpl.Body = strings.Join(bodyLines, "\n")
+
+	return tmpl, nil
+}
+
+// Inject substitutes every occurrence of marker in the request line and
+// headers with the raw payload (same as it's the caller's job to pre-escape
+// a payload placed inside a URL), and in the body with a payload escaped for
+// the template's Content-Type via bodyEscape, then resolves the result
+// against scheme/host into a ready-to-send RequestConfig. Content-Length is
+// dropped since substitution can change the body's length.
+func (t *RequestTemplate) Inject(scheme, marker, payload string) (RequestConfig, error) {
+	host := t.Headers.Get("Host")
+	if host == "" {
+		return RequestConfig{}, fmt.Errorf("request template has no Host header to build a URL from")
+	}
+
+	path := strings.ReplaceAll(t.Path, marker, payload)
+	fullURL := fmt.Sprintf("%s://%s%s", scheme, host, path)
+
+	headers := make(http.Header, len(t.Headers))
+	for name, values := range t.Headers {
+		for _, value := range values {
+			headers.Add(name, strings.ReplaceAll(value, marker, payload))
+		}
+	}
+	headers.Del("Content-Length")
+
+	body := strings.ReplaceAll(t.Body, marker, bodyEscape(t.Headers.Get("Content-Type"), payload))
+
+	return RequestConfig{
+		URL:     fullURL,
+		Method:  t.Method,
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+// bodyEscape escapes payload for substitution into a request body according
+// to contentType: form-urlencoded values are percent-encoded, JSON string
+// values are JSON-escaped (the surrounding quotes are trimmed back off since
+// the marker is expected to already sit inside a quoted JSON string in the
+// template), and multipart part bodies are escaped via multipartEscape. Any
+// other/unrecognized Content-Type is passed through unescaped, same as for
+// the URL.
+func bodyEscape(contentType, payload string) string {
+	switch {
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		return url.QueryEscape(payload)
+	case strings.Contains(contentType, "application/json"):
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return payload
+		}
+		return strings.Trim(string(encoded), `"`)
+	case strings.Contains(contentType, "multipart/form-data"):
+		return multipartEscape(contentType, payload)
+	default:
+		return payload
+	}
+}
+
+// multipartEscape prepares payload for substitution into a multipart part
+// body: it strips CRLF/LF so the payload can't terminate the current part
+// early and smuggle in a forged header or an extra part, and strips any
+// occurrence of the template's own boundary delimiter so the payload can't
+// prematurely close the part it's meant to land inside. The payload doesn't
+// otherwise need escaping — a multipart part body is raw bytes.
+func multipartEscape(contentType, payload string) string {
+	escaped := strings.NewReplacer("\r\n", "", "\n", "", "\r", "").Replace(payload)
+
+	if boundary := multipartBoundary(contentType); boundary != "" {
+		escaped = strings.ReplaceAll(escaped, "--"+boundary, "")
+	}
+
+	return escaped
+}
+
+// multipartBoundary extracts the boundary parameter from a multipart
+// Content-Type header, returning "" if it's missing or malformed
+func multipartBoundary(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["boundary"]
+}
+
+// WithHeader returns a copy of the template with name set to value,
+// overwriting any existing occurrence(s) — used for --header/--cookie
+// overrides layered on top of a parsed request file
+func (t *RequestTemplate) WithHeader(name, value string) *RequestTemplate {
+	clone := *t
+	clone.Headers = t.Headers.Clone()
+	clone.Headers.Set(name, value)
+	return &clone
+}
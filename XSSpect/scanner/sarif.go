@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sarifSchemaURI and sarifVersion pin the report to SARIF 2.1.0, the version
+// GitHub Advanced Security and GitLab SAST both ingest directly
+const (
+	sarifSchemaURI = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion   = "2.1.0"
+)
+
+// sarifLog is the root of a SARIF log, trimmed to the fields XSSpect needs
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// SaveSARIFReport writes the scan results in SARIF 2.1.0 format so they can
+// be uploaded as-is to GitHub Advanced Security, GitLab SAST, or DefectDojo
+func SaveSARIFReport(summary *ScanSummary, outputPath string) error {
+	log := sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name: "XSSpect",
+						Rules: []sarifRule{
+							{ID: "xss-reflected", Name: "ReflectedXSS", ShortDescription: sarifMessage{Text: "Reflected cross-site scripting"}},
+							{ID: "xss-dom", Name: "DOMXSS", ShortDescription: sarifMessage{Text: "DOM-based cross-site scripting"}},
+						},
+					},
+				},
+				Results: sarifResultsFor(summary),
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF report: %w", err)
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save SARIF report: %w", err)
+	}
+	return nil
+}
+
+// sarifResultsFor converts every RawReflection finding into a SARIF result;
+// anything that didn't reach RawReflection (escaped, blocked, no reflection)
+// isn't a finding and is left out
+func sarifResultsFor(summary *ScanSummary) []sarifResult {
+	var results []sarifResult
+	for _, result := range summary.Results {
+		if result.ReflectionType != RawReflection {
+			continue
+		}
+
+		ruleID := "xss-reflected"
+		if result.VulnClass == DOM {
+			ruleID = "xss-dom"
+		}
+
+		level := "warning"
+		if result.BrowserVerified {
+			level = "error"
+		}
+
+		uri := result.SourceURL
+		if uri == "" {
+			uri = summary.TargetURL
+		}
+
+		message := fmt.Sprintf("XSS payload %q reflected in parameter %q", result.Payload, result.Parameter)
+		if len(result.Mutations) > 0 {
+			message += fmt.Sprintf(" (bypass chain: %s)", strings.Join(result.Mutations, ","))
+		}
+
+		results = append(results, sarifResult{
+			RuleID: ruleID,
+			Level:  level,
+			Message: sarifMessage{
+				Text: message,
+			},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}},
+					LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: result.Parameter}},
+				},
+			},
+			PartialFingerprints: map[string]string{
+				"xsspectFingerprint/v1": sarifFingerprint(result),
+			},
+		})
+	}
+	return results
+}
+
+// sarifFingerprint hashes parameter+payload+context so the same finding
+// dedupes across repeated scans even as other results shift position
+func sarifFingerprint(result ScanResult) string {
+	sum := sha256.Sum256([]byte(result.Parameter + "\x00" + result.Payload + "\x00" + result.Context.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,270 @@
+package scanner
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// generateToken returns a short random token used to uniquely tag a stored
+// payload so it can be told apart from any other injected payload when it
+// turns up on a different page
+func generateToken() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// StoredXSSPayload builds the uniquely-tagged marker payload injected for
+// the stored-XSS workflow: if it survives storage and later renders, the
+// marker script sets window.__xsspect to the token
+func StoredXSSPayload() (token, payload string, err error) {
+	token, err = generateToken()
+	if err != nil {
+		return "", "", err
+	}
+	payload = fmt.Sprintf(`<script>window.__xsspect='%s'</script>`, token)
+	return token, payload, nil
+}
+
+// RunStoredXSSWorkflow injects a uniquely-tagged payload into one parameter
+// of sourcePage, then visits every other crawled page checking for the
+// out-of-band marker. A hit means the payload was stored server-side and
+// rendered/executed somewhere other than where it was submitted. If param
+// belongs to one of sourcePage's forms, the payload is submitted through
+// that form (other inputs filled with a benign placeholder so required
+// fields don't block submission); otherwise it falls back to injecting
+// param as a bare query parameter, same as before forms were enumerated.
+func RunStoredXSSWorkflow(bv *BrowserVerifier, sourcePage CrawledPage, param string, otherPages []CrawledPage) (*ScanResult, error) {
+	token, payload, err := StoredXSSPayload()
+	if err != nil {
+		return nil, err
+	}
+
+	injectURL, err := submitStoredPayload(bv, sourcePage, param, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, page := range otherPages {
+		if page.URL == sourcePage.URL {
+			continue
+		}
+
+		found, err := bv.VerifyStoredMarker(page.URL, token)
+		if err != nil {
+			continue
+		}
+		if found {
+			return &ScanResult{
+				Parameter:       param,
+				Payload:         payload,
+				ReflectionType:  RawReflection,
+				BrowserVerified: true,
+				VulnClass:       Stored,
+				SourceURL:       injectURL,
+				SinkURL:         page.URL,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// submitStoredPayload submits payload for param on sourcePage: through
+// whichever form declares param as one of its inputs, if any does, or as a
+// bare query parameter otherwise (the path used before forms were wired up,
+// still needed for params the crawler only saw in a URL's query string).
+// Returns the URL the submission is attributed to, for ScanResult.SourceURL.
+func submitStoredPayload(bv *BrowserVerifier, sourcePage CrawledPage, param, payload string) (string, error) {
+	for _, form := range sourcePage.Forms {
+		if containsInput(form.Inputs, param) {
+			return submitForm(bv, sourcePage.URL, form, param, payload)
+		}
+	}
+
+	injectURL, err := BuildRequestURL(sourcePage.URL, param, payload)
+	if err != nil {
+		return "", err
+	}
+	if _, err := bv.VerifyXSSExecution(injectURL); err != nil {
+		return "", fmt.Errorf("failed to submit stored payload: %w", err)
+	}
+	return injectURL, nil
+}
+
+// submitForm fills every input on form with a benign placeholder except
+// param, which gets payload, then submits it against pageURL: GET forms
+// navigate through the browser like any other link (so any immediate
+// execution is also caught), POST forms are sent directly via SendRequest
+// since BrowserVerifier has no POST-form primitive.
+func submitForm(bv *BrowserVerifier, pageURL string, form Form, param, payload string) (string, error) {
+	action := form.Action
+	if action == "" {
+		action = pageURL
+	}
+	actionURL, err := resolveFormAction(pageURL, action)
+	if err != nil {
+		return "", err
+	}
+
+	values := url.Values{}
+	for _, input := range form.Inputs {
+		if input == param {
+			values.Set(input, payload)
+		} else {
+			values.Set(input, "xsspect")
+		}
+	}
+
+	if form.Method == "POST" {
+		result := SendRequest(RequestConfig{
+			URL:     actionURL,
+			Method:  "POST",
+			Headers: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+			Body:    values.Encode(),
+		})
+		if result.Error != nil {
+			return "", fmt.Errorf("failed to submit stored payload form: %w", result.Error)
+		}
+		return actionURL, nil
+	}
+
+	submitURL, err := url.Parse(actionURL)
+	if err != nil {
+		return "", err
+	}
+	submitURL.RawQuery = values.Encode()
+
+	if _, err := bv.VerifyXSSExecution(submitURL.String()); err != nil {
+		return "", fmt.Errorf("failed to submit stored payload: %w", err)
+	}
+	return submitURL.String(), nil
+}
+
+// resolveFormAction resolves a form's action attribute (often relative)
+// against the page URL it was found on
+func resolveFormAction(pageURL, action string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	rel, err := url.Parse(action)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(rel).String(), nil
+}
+
+// containsInput reports whether name is one of inputs
+func containsInput(inputs []string, name string) bool {
+	for _, input := range inputs {
+		if input == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RunStoredXSSWorkflowURLs submits a uniquely-tagged marker payload for param
+// to submitURL, then fetches verifyURL and reports whether it rendered and
+// executed there. Unlike RunStoredXSSWorkflow, which checks every page the
+// crawler found, this is for setups where the submission and storage
+// surfaces are two specific, already-known endpoints (--submit-url/--verify-url).
+func RunStoredXSSWorkflowURLs(bv *BrowserVerifier, submitURL, verifyURL, param string) (*ScanResult, error) {
+	token, payload, err := StoredXSSPayload()
+	if err != nil {
+		return nil, err
+	}
+
+	injectURL, err := BuildRequestURL(submitURL, param, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := bv.VerifyXSSExecution(injectURL); err != nil {
+		return nil, fmt.Errorf("failed to submit stored payload: %w", err)
+	}
+
+	found, err := bv.VerifyStoredMarker(verifyURL, token)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	return &ScanResult{
+		Parameter:       param,
+		Payload:         payload,
+		ReflectionType:  RawReflection,
+		BrowserVerified: true,
+		VulnClass:       Stored,
+		SourceURL:       injectURL,
+		SinkURL:         verifyURL,
+	}, nil
+}
+
+// RunDOMXSSWorkflowQuery drives the browser to baseURL with payload placed in
+// param's query string, which catches client-side code that reads
+// location.search rather than location.hash
+func RunDOMXSSWorkflowQuery(bv *BrowserVerifier, baseURL, param, payload string) (*ScanResult, error) {
+	testURL, err := BuildRequestURL(baseURL, param, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, err := bv.VerifyXSSExecution(testURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	return &ScanResult{
+		Parameter:       param,
+		Payload:         payload,
+		ReflectionType:  RawReflection,
+		VulnClass:       DOM,
+		SourceURL:       testURL,
+		SinkURL:         testURL,
+		BrowserVerified: true,
+		XSSEventType:    hits[0].Sink,
+		SinkHits:        hits,
+	}, nil
+}
+
+// RunDOMXSSWorkflow drives the browser to baseURL with payload placed in the
+// URL fragment (location.hash), which many client-side routers read and
+// inject into the DOM without ever sending it to the server, and reports any
+// dangerous sink that fired
+func RunDOMXSSWorkflow(bv *BrowserVerifier, baseURL, payload string) (*ScanResult, error) {
+	testURL, err := BuildFragmentURL(baseURL, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, err := bv.VerifyXSSExecution(testURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	return &ScanResult{
+		Payload:         payload,
+		ReflectionType:  RawReflection,
+		VulnClass:       DOM,
+		SourceURL:       testURL,
+		SinkURL:         testURL,
+		BrowserVerified: true,
+		XSSEventType:    hits[0].Sink,
+		SinkHits:        hits,
+	}, nil
+}
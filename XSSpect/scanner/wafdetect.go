@@ -0,0 +1,191 @@
+package scanner
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+)
+
+// WAFInfo describes a Web Application Firewall identified in front of the target
+type WAFInfo struct {
+	Name           string
+	Vendor         string
+	BlockSignature string
+}
+
+// wafProbePayloads are benign-but-suspicious strings used to provoke a WAF
+// into blocking the request, without actually attempting exploitation
+var wafProbePayloads = []string{
+	`<script>alert(1)</script>`,
+	`' OR 1=1--`,
+	`../../../../etc/passwd`,
+	`<img src=x onerror=alert(1)>`,
+}
+
+// wafSignature maps a header or body marker to the WAF it identifies
+type wafSignature struct {
+	name   string
+	vendor string
+	marker string
+	inBody bool
+}
+
+var wafSignatures = []wafSignature{
+	{name: "Cloudflare", vendor: "Cloudflare", marker: "cf-ray"},
+	{name: "Cloudflare", vendor: "Cloudflare", marker: "__cfduid"},
+	{name: "Akamai", vendor: "Akamai", marker: "akamai-"},
+	{name: "AWS WAF", vendor: "Amazon", marker: "x-amzn-"},
+	{name: "ModSecurity", vendor: "Trustwave", marker: "mod_security", inBody: true},
+	{name: "Wallarm", vendor: "Wallarm", marker: "nemesida"},
+}
+
+// blockStatusCodes are HTTP status codes commonly returned when a WAF blocks a request
+var blockStatusCodes = map[int]bool{
+	403: true,
+	406: true,
+	419: true,
+	429: true,
+	503: true,
+}
+
+// DetectWAF probes the target once with a handful of benign-but-suspicious
+// payloads and inspects the responses' headers, cookies and body markers to
+// identify common WAFs; a block status code alone identifies no WAF here
+// (see DefaultCheckBlockFunc for status codes). Returns nil if no WAF could be
+// identified, which is the normal case for an unprotected target.
+func DetectWAF(baseURL, parameter, method string) (*WAFInfo, error) {
+	for _, probe := range wafProbePayloads {
+		testURL, err := BuildRequestURL(baseURL, parameter, probe)
+		if err != nil {
+			continue
+		}
+
+		result := SendRequest(RequestConfig{URL: testURL, Method: method})
+		if result.Error != nil {
+			continue
+		}
+
+		if waf := identifyWAF(result); waf != nil {
+			return waf, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// identifyWAF inspects a single response's headers, cookies and body for
+// known WAF signatures
+func identifyWAF(result RequestResult) *WAFInfo {
+	bodyLower := strings.ToLower(result.ResponseBody)
+
+	for _, sig := range wafSignatures {
+		if sig.inBody {
+			if strings.Contains(bodyLower, sig.marker) {
+				return &WAFInfo{Name: sig.name, Vendor: sig.vendor, BlockSignature: sig.marker}
+			}
+			continue
+		}
+
+		if headersContainMarker(result.Headers, sig.marker) {
+			return &WAFInfo{Name: sig.name, Vendor: sig.vendor, BlockSignature: sig.marker}
+		}
+	}
+
+	return nil
+}
+
+// headersContainMarker checks response headers (including Set-Cookie) for a
+// case-insensitive substring match against marker, covering both header
+// names (e.g. "cf-ray") and header/cookie values (e.g. "__cfduid=...")
+func headersContainMarker(headers map[string][]string, marker string) bool {
+	for name, values := range headers {
+		if strings.Contains(strings.ToLower(name), marker) {
+			return true
+		}
+		for _, value := range values {
+			if strings.Contains(strings.ToLower(value), marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DefaultSessionRenewal is how many requests CookieSession lets pass before
+// renewing the session against the base URL, for a WAF whose per-session
+// cookie expires or otherwise gets rotated partway through a scan
+const DefaultSessionRenewal = 25
+
+// CookieSession carries cookies across requests once a WAF is detected:
+// many WAFs track requests by session and are far likelier to block ones
+// that show up without the cookies the target itself issued, so scanning
+// through a persistent jar (and periodically renewing it) evades that
+// without touching payload generation at all.
+type CookieSession struct {
+	Jar http.CookieJar
+
+	baseURL      string
+	method       string
+	renewEvery   int
+	sinceRenewal int
+}
+
+// NewCookieSession creates a CookieSession backed by an in-memory cookie
+// jar, seeded immediately by one request to baseURL so scanning starts with
+// a live session already in hand
+func NewCookieSession(baseURL, method string) (*CookieSession, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &CookieSession{
+		Jar:        jar,
+		baseURL:    baseURL,
+		method:     method,
+		renewEvery: DefaultSessionRenewal,
+	}
+	if err := session.Renew(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Renew fetches baseURL again to refresh the jar with a current session,
+// resetting the request count Track uses to decide when to renew next
+func (s *CookieSession) Renew() error {
+	result := SendRequest(RequestConfig{URL: s.baseURL, Method: s.method, Jar: s.Jar})
+	s.sinceRenewal = 0
+	return result.Error
+}
+
+// Track records that one more request has gone out on this session and
+// renews it once DefaultSessionRenewal requests have passed since the last
+// renewal, so a session cookie that expires mid-scan doesn't sit stale for
+// every job after it. Renewal failures are left for the next request's own
+// error handling to surface; Track never fails scanning on their account.
+func (s *CookieSession) Track() {
+	s.sinceRenewal++
+	if s.sinceRenewal >= s.renewEvery {
+		s.Renew()
+	}
+}
+
+// CheckBlockFunc decides whether a given response represents a WAF block
+// rather than a normal application response
+type CheckBlockFunc func(result RequestResult) bool
+
+// DefaultCheckBlockFunc returns a CheckBlockFunc tailored to the detected WAF
+// (or a generic status-code-only check when waf is nil)
+func DefaultCheckBlockFunc(waf *WAFInfo) CheckBlockFunc {
+	return func(result RequestResult) bool {
+		if !blockStatusCodes[result.StatusCode] {
+			return false
+		}
+		if waf == nil {
+			return true
+		}
+		return strings.Contains(strings.ToLower(result.ResponseBody), strings.ToLower(waf.BlockSignature)) ||
+			headersContainMarker(result.Headers, strings.ToLower(waf.BlockSignature))
+	}
+}